@@ -1,11 +1,11 @@
-
 package main
 
 import (
 	"context"
 	"fmt"
 	"os"
-	"github.com/edsonmichaque/craft/internal/commands/craftctl"
+
+	craftctl "github.com/edsonmichaque/craft/internal/commands/craftctl"
 )
 
 func main() {
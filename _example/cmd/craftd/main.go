@@ -1,11 +1,11 @@
-
 package main
 
 import (
 	"context"
 	"fmt"
 	"os"
-	"github.com/edsonmichaque/craft/internal/commands/craftd"
+
+	craftd "github.com/edsonmichaque/craft/internal/commands/craftd"
 )
 
 func main() {
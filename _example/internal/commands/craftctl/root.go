@@ -1,19 +1,12 @@
-
-
 package craftctl
 
-
 import (
 	"context"
-	"fmt"
-	"log"
 	"os"
-	
-"github.com/urfave/cli/v2"
 
+	"github.com/urfave/cli/v2"
 )
 
-
 type Context struct {
 	ConfigPath string
 	Debug      bool
@@ -53,4 +46,3 @@ func CmdRoot(ctx context.Context, appCtx *Context) *cli.App {
 func Execute(ctx context.Context, appCtx *Context) error {
 	return CmdRoot(ctx, appCtx).Run(os.Args)
 }
-
@@ -1,19 +1,12 @@
-
-
 package craftctl
 
-
 import (
 	"context"
-	"fmt"
 	"log"
-	"os"
-	
-"github.com/urfave/cli/v2"
 
+	"github.com/urfave/cli/v2"
 )
 
-
 func CmdServer(ctx context.Context, appCtx *Context) *cli.Command {
 	var (
 		port int
@@ -45,4 +38,3 @@ func CmdServer(ctx context.Context, appCtx *Context) *cli.Command {
 		},
 	}
 }
-
@@ -1,20 +1,13 @@
-
-
 package craftctl
 
-
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
-	
-"github.com/urfave/cli/v2"
-"github.com/edsonmichaque/craft/pkg/version"
 
+	"github.com/edsonmichaque/craft/pkg/version"
+	"github.com/urfave/cli/v2"
 )
 
-
 func CmdVersion(ctx context.Context, appCtx *Context) *cli.Command {
 	return &cli.Command{
 		Name:  "version",
@@ -27,4 +20,3 @@ func CmdVersion(ctx context.Context, appCtx *Context) *cli.Command {
 		},
 	}
 }
-
@@ -2,8 +2,8 @@ package config
 
 // LoggerConfig holds all logging-related configuration
 type LoggerConfig struct {
-	Level  string            ` + "`mapstructure:\"level\" yaml:\"level\"`" + `
-	Format string            ` + "`mapstructure:\"format\" yaml:\"format\"`" + `
-	Output string            ` + "`mapstructure:\"output\" yaml:\"output\"`" + `
-	Fields map[string]string ` + "`mapstructure:\"fields\" yaml:\"fields\"`" + `
-}
\ No newline at end of file
+	Level  string            `mapstructure:"level" yaml:"level"`
+	Format string            `mapstructure:"format" yaml:"format"`
+	Output string            `mapstructure:"output" yaml:"output"`
+	Fields map[string]string `mapstructure:"fields" yaml:"fields"`
+}
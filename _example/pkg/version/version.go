@@ -38,14 +38,14 @@ var (
 
 // Info holds all version information.
 type Info struct {
-	Version      string            ` + "`json:\"version\"`" + `
-	GitCommit    string            ` + "`json:\"gitCommit\"`" + `
-	GitBranch    string            ` + "`json:\"gitBranch\"`" + `
-	BuildTime    string            ` + "`json:\"buildTime\"`" + `
-	BuildUser    string            ` + "`json:\"buildUser\"`" + `
-	GoVersion    string            ` + "`json:\"goVersion\"`" + `
-	Platform     string            ` + "`json:\"platform\"`" + `
-	Dependencies map[string]string ` + "`json:\"dependencies,omitempty\"`" + `
+	Version      string            `json:"version"`
+	GitCommit    string            `json:"gitCommit"`
+	GitBranch    string            `json:"gitBranch"`
+	BuildTime    string            `json:"buildTime"`
+	BuildUser    string            `json:"buildUser"`
+	GoVersion    string            `json:"goVersion"`
+	Platform     string            `json:"platform"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
 }
 
 // Get returns the version information as a structured object.
@@ -143,7 +143,7 @@ func getDependencyVersions() map[string]string {
 // BuildContext returns a map of build-time variables.
 func BuildContext() map[string]string {
 	return map[string]string{
-		"version":    Version,
+		"version":   Version,
 		"gitCommit": GitCommit,
 		"gitBranch": GitBranch,
 		"buildTime": BuildTime,
@@ -159,4 +159,4 @@ func Validate() error {
 		return fmt.Errorf("version information not properly initialized")
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -111,7 +111,7 @@ create_tag() {
         log_error "Invalid version format: $version"
         log_error "Version must match pattern: $VERSION_PATTERN"
         exit 1
-    }
+    fi
     
     # Check if tag already exists
     if git rev-parse "$version" >/dev/null 2>&1; then
@@ -1,5 +1,4 @@
-
-const lintTaskTemplate = `#!/usr/bin/env bash
+#!/usr/bin/env bash
 # Lint task script
 # Runs linters and code quality checks
 
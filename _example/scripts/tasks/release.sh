@@ -14,7 +14,7 @@ main() {
     if ! is_working_directory_clean; then
         log_error "Working directory is not clean"
         exit 1
-    }
+    fi
     
     # Get next version
     next_version=$(get_next_version "$bump_type")
@@ -22,7 +22,7 @@ main() {
     # Validate version format
     if ! validate_version "$next_version"; then
         exit 1
-    }
+    fi
     
     # Generate changelog
     local changelog
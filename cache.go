@@ -0,0 +1,71 @@
+package craft
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// templateCache keeps the template sets parsed by a Manager, keyed by the
+// list of templates, so that templates shared by several files, such as the
+// base of the commands, are read and parsed once.
+type templateCache struct {
+	mu   sync.Mutex
+	sets map[string]*templateSet
+}
+
+// templateSet is a parsed list of templates, or the errors parsing it.
+type templateSet struct {
+	once   sync.Once
+	tpl    *template.Template
+	layers []string
+	err    error
+}
+
+// get returns the set for templates, parsing it with parse on first use.
+// Concurrent callers asking for the same set wait for a single parse.
+func (c *templateCache) get(templates []string, parse func([]string) (*template.Template, []string, error)) *templateSet {
+	key := strings.Join(templates, "\x00")
+
+	c.mu.Lock()
+	if c.sets == nil {
+		c.sets = make(map[string]*templateSet)
+	}
+
+	set, ok := c.sets[key]
+	if !ok {
+		set = &templateSet{}
+		c.sets[key] = set
+	}
+	c.mu.Unlock()
+
+	set.once.Do(func() {
+		set.tpl, set.layers, set.err = parse(templates)
+	})
+
+	return set
+}
+
+// reset drops every parsed set, e.g. when the templates change.
+func (c *templateCache) reset() {
+	c.mu.Lock()
+	c.sets = nil
+	c.mu.Unlock()
+}
+
+// errorFor returns the errors of the set, attributed to the file at dst.
+func (s *templateSet) errorFor(dst string) error {
+	var errs Errors
+
+	for _, err := range flattenErrors(s.err) {
+		if te, ok := err.(*TemplateError); ok {
+			te := *te
+			te.Dst = dst
+			err = &te
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,90 @@
+package craft_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// countingFS counts the files opened in FS.
+type countingFS struct {
+	fs.FS
+
+	mu     sync.Mutex
+	opened map[string]int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.mu.Lock()
+	c.opened[name]++
+	c.mu.Unlock()
+
+	return c.FS.Open(name)
+}
+
+func TestTemplateCache(t *testing.T) {
+	templates := &countingFS{
+		FS: fstest.MapFS{
+			"base.tmpl": {Data: []byte(`{{define "greeting"}}Hello{{end}}`)},
+			"file.tmpl": {Data: []byte(`{{template "greeting"}}, {{.ProjectName}}`)},
+		},
+		opened: make(map[string]int),
+	}
+
+	registry, err := craft.NewRegistry(craft.NewGenerator("files", "Files", func(data craft.Data) (map[string]craft.RenderOptions, error) {
+		out := make(map[string]craft.RenderOptions)
+		for i := 0; i < 20; i++ {
+			out[fmt.Sprintf("file%d.txt", i)] = craft.RenderOptions{
+				Templates: []string{"base.tmpl", "file.tmpl"},
+				Execute:   "file.tmpl",
+				Data:      data,
+			}
+		}
+
+		return out, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := &craft.Manager{
+		Registry: registry,
+		Options:  craft.Options{Templates: templates},
+	}
+
+	for i := 0; i < 2; i++ {
+		files, err := manager.Generate(context.Background(), testData(), "files")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(files["file7.txt"]); got != "Hello, example" {
+			t.Errorf("got file7.txt %q, want %q", got, "Hello, example")
+		}
+	}
+
+	// Rendering 40 files over two calls parses the set once.
+	for _, name := range []string{"base.tmpl", "file.tmpl"} {
+		if n := templates.opened[name]; n != 1 {
+			t.Errorf("%s read %d times, want 1", name, n)
+		}
+	}
+
+	// Configure drops the parsed sets.
+	if err := manager.Configure(craft.Options{Templates: templates}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.Generate(context.Background(), testData(), "files"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := templates.opened["file.tmpl"]; n != 2 {
+		t.Errorf("file.tmpl read %d times after Configure, want 2", n)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runAdd adds generators or features to the project in --dir, writing the
+// files they produce and recording them in the lock file. The files already
+// recorded are left to craft update.
+func runAdd(args []string) int {
+	if len(args) > 0 && args[0] == "command" {
+		return runAddCommand(args[1:])
+	}
+
+	flags := newFlagSet("add", "[flags] <generator|feature>...\n       craft add command [flags] <binary> <name>", "Add generators, see craft list generators, or features, see craft list\nfeatures, to an existing project.")
+	dir := flags.String("dir", ".", "Directory of the project")
+	dryRun := flags.Bool("dry-run", false, "Print the files that would be written without writing them")
+	onConflict := flags.String("on-conflict", string(craft.ConflictFail), "What to do with existing files that differ from the generated ones: skip, overwrite, backup, fail or prompt")
+	sources := addSourceFlags(flags)
+
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if flags.NArg() == 0 {
+		return usageError(flags, "Please provide the generators or features to add")
+	}
+
+	policy, err := craft.ParseConflictPolicy(*onConflict)
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+
+	lock, err := craft.ReadLock(*dir)
+	if err != nil {
+		return failure("Failed to load project: %v", err)
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	manager, err := sources.manager(ctx)
+	if err != nil {
+		return failure("Failed to load generators: %v", err)
+	}
+
+	data := lock.Data
+
+	selected := lock.Generators
+	if len(selected) == 0 {
+		selected = manager.Registry.Names()
+	}
+
+	changed := false
+
+	for _, component := range flags.Args() {
+		if _, ok := manager.Registry.Get(component); ok {
+			if contains(selected, component) {
+				fmt.Printf("Generator %s is already part of the project\n", component)
+				continue
+			}
+
+			selected = append(selected, component)
+			changed = true
+
+			continue
+		}
+
+		if _, ok := craft.LookupFeature(component); ok {
+			if data.HasFeature(component) {
+				fmt.Printf("Feature %s is already part of the project\n", component)
+				continue
+			}
+
+			if len(data.Includes) == 1 && data.Includes[0] == craft.NoFeatures {
+				data.Includes = nil
+			}
+
+			data.Includes = append(data.Includes, component)
+			changed = true
+
+			continue
+		}
+
+		return usageError(flags, "Unknown generator or feature %q, available generators: %s", component, strings.Join(manager.Registry.Names(), ", "))
+	}
+
+	if !changed {
+		return exitOK
+	}
+
+	rendered, err := manager.Render(ctx, data, selected...)
+	if err != nil {
+		return failure("Failed to generate files: %v", err)
+	}
+
+	// Files new to the project go through the conflict policy. Tracked files
+	// the change affects are rewritten when still as generated, and left to
+	// craft update otherwise.
+	var (
+		added   = make(map[string]craft.File)
+		updated = make(map[string]craft.File)
+		stale   []string
+	)
+
+	for path, f := range rendered {
+		locked, ok := lock.File(path)
+
+		switch {
+		case !ok:
+			added[path] = f
+		case locked.Hash == craft.Hash(f.Content):
+		case pristine(*dir, lock, path):
+			f.CreateOnly = false
+			updated[path] = f
+		default:
+			stale = append(stale, path)
+		}
+	}
+
+	sort.Strings(stale)
+
+	recorded := make(map[string]craft.File, len(added)+len(updated))
+	for _, m := range []map[string]craft.File{added, updated} {
+		for path, f := range m {
+			recorded[path] = f
+		}
+	}
+
+	if *dryRun {
+		changes, err := craft.Plan(*dir, recorded)
+		if err != nil {
+			return failure("Failed to plan changes: %v", err)
+		}
+
+		for _, change := range changes {
+			fmt.Printf("%-10s %s\n", change.Kind, change.Path)
+		}
+
+		printStale(stale)
+
+		return exitOK
+	}
+
+	files, results, err := craft.ResolveConflicts(*dir, added, policy, promptConflict)
+	if err != nil {
+		return failure("Failed to write files: %v", err)
+	}
+
+	for path, f := range updated {
+		files[path] = f
+		results = append(results, craft.WriteResult{Path: path, Action: craft.WriteOverwritten})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	lock.Version = craft.Version
+	lock.Data = data
+	lock.Generators = selected
+	lock.Record(recorded)
+
+	if files, err = withLock(files, lock, recorded); err != nil {
+		return failure("Failed to create lock file: %v", err)
+	}
+
+	if err := writeFiles(ctx, *dir, files); err != nil {
+		return writeFailed(err)
+	}
+
+	printSummary(results)
+	printStale(stale)
+
+	return exitOK
+}
+
+// printStale lists the tracked files that should change but were edited
+// since they were generated.
+func printStale(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "These files were edited and are not updated, run craft update to merge the changes:\n")
+
+	for _, path := range paths {
+		fmt.Fprintf(os.Stderr, "  %s\n", path)
+	}
+}
+
+// runAddCommand scaffolds a subcommand into a binary of the project in
+// --dir, registers it in its parent command and lists it in the README.
+func runAddCommand(args []string) int {
+	flags := newFlagSet("add command", "[flags] <binary> <name>", "Add a subcommand to a binary of an existing project, registered in the root\ncommand or in the command given with -parent.")
+	dir := flags.String("dir", ".", "Directory of the project")
+	parent := flags.String("parent", "", "Path of the parent command, such as server or \"db migrate\" (defaults to the root command)")
+	flagList := flags.String("flags", "", "Comma-separated list of name:type flags, the type being string, int, bool, float or duration (defaults to string)")
+	dryRun := flags.Bool("dry-run", false, "Print the files that would be written without writing them")
+	sources := addSourceFlags(flags)
+
+	// Flags may come after the arguments.
+	var positional []string
+
+	for {
+		if code, ok := parseFlags(flags, args); !ok {
+			return code
+		}
+
+		if flags.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(positional) != 2 {
+		return usageError(flags, "Please provide the binary and the name of the command")
+	}
+
+	cmdFlags, err := craft.ParseCommandFlags(*flagList)
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+
+	lock, err := craft.ReadLock(*dir)
+	if err != nil {
+		return failure("Failed to load project: %v", err)
+	}
+
+	data := lock.Data
+	if !data.HasFeature("cli") {
+		return failure("The project does not include the cli feature, add it with craft add cli")
+	}
+
+	cmd := craft.Command{
+		Binary: positional[0],
+		Parent: *parent,
+		Name:   positional[1],
+		Flags:  cmdFlags,
+	}
+
+	data.Commands = append(data.Commands, cmd)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	manager, err := sources.manager(ctx)
+	if err != nil {
+		return failure("Failed to load generators: %v", err)
+	}
+
+	selected := lock.Generators
+	if len(selected) == 0 {
+		selected = manager.Registry.Names()
+	}
+
+	rendered, err := manager.Render(ctx, data, selected...)
+	if err != nil {
+		return failure("Failed to generate files: %v", err)
+	}
+
+	file, ok := rendered[cmd.File(data)]
+	if !ok {
+		return failure("Failed to generate files: %s was not generated", cmd.File(data))
+	}
+
+	if _, err := os.Stat(filepath.Join(*dir, file.Path)); err == nil {
+		return failure("%s already exists", file.Path)
+	}
+
+	registered, err := craft.RegisterCommand(*dir, data, cmd)
+	if err != nil {
+		return failure("Failed to register command: %v", err)
+	}
+
+	files := map[string]craft.File{file.Path: file}
+	results := []craft.WriteResult{{Path: file.Path, Action: craft.WriteCreated}}
+
+	// Files still as generated are replaced with the new output; the others
+	// are edited in place.
+	edit := func(f craft.File) {
+		if generated, ok := rendered[f.Path]; ok && pristine(*dir, lock, f.Path) {
+			f = generated
+		}
+
+		f.CreateOnly = false
+		files[f.Path] = f
+		results = append(results, craft.WriteResult{Path: f.Path, Action: craft.WriteOverwritten})
+	}
+
+	edit(registered)
+
+	if readme, err := os.ReadFile(filepath.Join(*dir, "README.md")); err == nil {
+		if content, ok := craft.ListCommand(readme, cmd); ok {
+			edit(craft.File{Path: "README.md", Content: content, Mode: craft.DefaultFileMode})
+		} else {
+			fmt.Fprintf(os.Stderr, "README.md has no Commands section, list %s there by hand\n", cmd.Path())
+		}
+	}
+
+	if *dryRun {
+		changes, err := craft.Plan(*dir, files)
+		if err != nil {
+			return failure("Failed to plan changes: %v", err)
+		}
+
+		for _, change := range changes {
+			fmt.Printf("%-10s %s\n", change.Kind, change.Path)
+		}
+
+		return exitOK
+	}
+
+	recorded := make(map[string]craft.File)
+	for path := range files {
+		if f, ok := rendered[path]; ok {
+			recorded[path] = f
+		}
+	}
+
+	lock.Version = craft.Version
+	lock.Data = data
+	lock.Record(recorded)
+
+	if files, err = withLock(files, lock, recorded); err != nil {
+		return failure("Failed to create lock file: %v", err)
+	}
+
+	if err := writeFiles(ctx, *dir, files); err != nil {
+		return writeFailed(err)
+	}
+
+	printSummary(results)
+
+	return exitOK
+}
+
+// pristine reports whether the file at path is still as craft generated it.
+func pristine(dir string, lock *craft.Lock, path string) bool {
+	locked, ok := lock.File(path)
+	if !ok {
+		return false
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+
+	return err == nil && craft.Hash(content) == locked.Hash
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+
+	return false
+}
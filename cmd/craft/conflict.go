@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptConflict asks on the terminal what to do with a file that differs
+// from the generated one.
+func promptConflict(change craft.Change) (craft.ConflictPolicy, error) {
+	for {
+		fmt.Fprintf(os.Stderr, "%s differs from the generated file. [o]verwrite, [s]kip, [b]ackup, show [d]iff, [a]bort? ", change.Path)
+
+		answer, err := stdin.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && answer != "") {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "overwrite":
+			return craft.ConflictOverwrite, nil
+		case "s", "skip":
+			return craft.ConflictSkip, nil
+		case "b", "backup":
+			return craft.ConflictBackup, nil
+		case "a", "abort":
+			return craft.ConflictFail, nil
+		case "d", "diff":
+			fmt.Fprint(os.Stderr, change.Diff())
+		}
+	}
+}
+
+// printSummary lists the files that were not left unchanged, followed by the
+// number of files per action.
+func printSummary(results []craft.WriteResult) {
+	counts := make(map[craft.WriteAction]int)
+
+	for _, result := range results {
+		counts[result.Action]++
+
+		if result.Action != craft.WriteUnchanged {
+			fmt.Printf("%-11s %s\n", result.Action, result.Path)
+		}
+	}
+
+	fmt.Printf("%d created, %d overwritten, %d skipped, %d backed up\n",
+		counts[craft.WriteCreated],
+		counts[craft.WriteOverwritten],
+		counts[craft.WriteSkipped],
+		counts[craft.WriteBackedUp],
+	)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// Statuses of a doctor check.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// runDoctor checks that the tools generated projects rely on are installed,
+// that the templates and plugins load, and that the project in --dir, if
+// any, is consistent with its lock file.
+func runDoctor(args []string) int {
+	flags := newFlagSet("doctor", "[flags]", "Check the environment, the templates, the plugins and the project in --dir.\nExits with 1 when a check fails.")
+	dir := flags.String("dir", ".", "Directory of the project to check")
+	sources := addSourceFlags(flags)
+
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	failed := false
+
+	report := func(status, check, format string, args ...interface{}) {
+		if status == checkFail {
+			failed = true
+		}
+
+		fmt.Printf("%-5s %-10s %s\n", status, check, fmt.Sprintf(format, args...))
+	}
+
+	for _, tool := range []string{"go", "git"} {
+		path, err := exec.LookPath(tool)
+		if err != nil {
+			report(checkWarn, tool, "not found in $PATH, generated projects need it")
+			continue
+		}
+
+		report(checkOK, tool, "%s", path)
+	}
+
+	if out, err := exec.Command("go", "version").Output(); err == nil {
+		report(checkOK, "toolchain", "%s", strings.TrimSpace(string(out)))
+	}
+
+	manager, err := sources.manager(context.Background())
+	if err != nil {
+		report(checkFail, "generators", "%v", err)
+	} else {
+		report(checkOK, "generators", "%s", strings.Join(manager.Registry.Names(), ", "))
+
+		issues, err := craft.Lint(manager.Options.Templates, manager.Registry)
+
+		switch {
+		case err != nil:
+			report(checkFail, "templates", "%v", err)
+		case len(issues) > 0:
+			report(checkWarn, "templates", "%d issue(s), run craft templates lint", len(issues))
+		default:
+			report(checkOK, "templates", "no issues")
+		}
+	}
+
+	doctorProject(*dir, report)
+
+	if failed {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// doctorProject checks the lock file of the project in dir and reports the
+// generated files changed or removed since.
+func doctorProject(dir string, report func(status, check, format string, args ...interface{})) {
+	if _, err := os.Stat(filepath.Join(dir, craft.LockFileName)); errors.Is(err, fs.ErrNotExist) {
+		report(checkOK, "project", "no craft project in %s", dir)
+		return
+	}
+
+	lock, err := craft.ReadLock(dir)
+	if err != nil {
+		report(checkFail, "project", "%v", err)
+		return
+	}
+
+	if lock.Version != craft.Version {
+		report(checkWarn, "project", "generated by craft %s, this is craft %s, run craft update", lock.Version, craft.Version)
+	} else {
+		report(checkOK, "project", "generated by craft %s", lock.Version)
+	}
+
+	if err := lock.Data.Validate(); err != nil {
+		report(checkFail, "data", "%v", err)
+	}
+
+	var modified, missing int
+
+	for _, f := range lock.Files {
+		content, err := os.ReadFile(filepath.Join(dir, f.Path))
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			missing++
+		case err != nil:
+			report(checkFail, "files", "%v", err)
+			return
+		case craft.Hash(content) != f.Hash:
+			modified++
+		}
+	}
+
+	report(checkOK, "files", "%d generated, %d modified, %d removed", len(lock.Files), modified, missing)
+}
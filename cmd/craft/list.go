@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runList prints the generators, templates, licenses or features craft
+// knows about.
+func runList(args []string) int {
+	flags := newFlagSet("list", "generators|templates|licenses|features [flags]", "List what craft can generate.")
+	sources := addSourceFlags(flags)
+
+	// Flags may come before or after what to list.
+	var positional []string
+
+	for {
+		if code, ok := parseFlags(flags, args); !ok {
+			return code
+		}
+
+		if flags.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(positional) != 1 {
+		return usageError(flags, "Please provide what to list")
+	}
+
+	switch what := positional[0]; what {
+	case "generators":
+		manager, err := sources.manager(context.Background())
+		if err != nil {
+			return failure("Failed to load generators: %v", err)
+		}
+
+		for _, name := range manager.Registry.Names() {
+			g, _ := manager.Registry.Get(name)
+
+			var extra []string
+			if features := g.Features(); len(features) > 0 {
+				extra = append(extra, "features: "+strings.Join(features, ", "))
+			}
+			if deps := g.Dependencies(); len(deps) > 0 {
+				extra = append(extra, "depends on: "+strings.Join(deps, ", "))
+			}
+
+			line := fmt.Sprintf("%-12s %s", name, g.Description())
+			if len(extra) > 0 {
+				line += " (" + strings.Join(extra, "; ") + ")"
+			}
+
+			fmt.Println(line)
+		}
+	case "templates":
+		layers, err := templateLayers(*sources.templatesDir)
+		if err != nil {
+			return failure("Failed to load templates: %v", err)
+		}
+
+		err = fs.WalkDir(layers, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			layer, err := layers.Layer(path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%-60s %s\n", path, layer)
+
+			return nil
+		})
+		if err != nil {
+			return failure("Failed to list templates: %v", err)
+		}
+	case "licenses":
+		names := make([]string, 0, len(craft.Licenses))
+		for name := range craft.Licenses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("%-14s %s\n", name, craft.Licenses[name])
+		}
+	case "features":
+		for _, f := range craft.Features {
+			line := fmt.Sprintf("%-12s %s", f.Name, f.Description)
+			if len(f.Requires) > 0 {
+				line += " (requires " + strings.Join(f.Requires, ", ") + ")"
+			}
+
+			fmt.Println(line)
+		}
+	default:
+		return usageError(flags, "Unknown list %q", what)
+	}
+
+	return exitOK
+}
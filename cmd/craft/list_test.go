@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{args: []string{"list", "-h"}, code: exitOK},
+		{args: []string{"list", "--help"}, code: exitOK},
+		{args: []string{"list", "licenses", "-h"}, code: exitOK},
+		{args: []string{"help", "list"}, code: exitOK},
+		{args: []string{"list", "licenses"}, code: exitOK},
+		{args: []string{"list", "-templates-dir", "", "features"}, code: exitOK},
+		{args: []string{"list"}, code: exitUsage},
+		{args: []string{"list", "licenses", "features"}, code: exitUsage},
+		{args: []string{"list", "-unknown"}, code: exitUsage},
+		{args: []string{"list", "commands"}, code: exitUsage},
+	}
+
+	for _, tt := range tests {
+		if code := run(tt.args); code != tt.code {
+			t.Errorf("craft %v: got exit code %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
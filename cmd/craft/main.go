@@ -3,11 +3,16 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
+	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/edsonmichaque/craft"
 )
@@ -15,140 +20,252 @@ import (
 //go:embed templates
 var templates embed.FS
 
+// embeddedLayer names the layer of templates built into craft.
+const embeddedLayer = "embedded"
+
+// Exit codes of every command.
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitInterrupted = 130
+)
+
+// command is a subcommand of craft.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{name: "new", summary: "Generate a new project", run: runNew},
+	{name: "add", summary: "Add generators or features to an existing project", run: runAdd},
+	{name: "list", summary: "List generators, templates, licenses or features", run: runList},
+	{name: "update", summary: "Regenerate a project and merge the result with local changes", run: runUpdate},
+	{name: "doctor", summary: "Check the environment and the project for problems", run: runDoctor},
+	{name: "spec", summary: "Create a project spec file", run: runSpec},
+	{name: "templates", summary: "Work with templates", run: runTemplates},
+}
+
 func main() {
-	allGenerators := map[string]craft.Generator{
-		"config":   craft.GenerateConfig,
-		"docker":   craft.GenerateDockerFiles,
-		"script":   craft.GenerateScripts,
-		"license":  craft.GenerateLicense,
-		"commands": craft.GenerateCommands,
-		"version":  craft.GenerateVersion,
-		"common":   craft.GenerateCommonFiles,
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
 	}
 
-	data := parseFlags()
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			return run([]string{args[1], "-h"})
+		}
 
-	manager := craft.Manager{
-		Generators: allGenerators,
-		Options: craft.Options{
-			Templates: templates,
-		},
+		usage()
+		return exitOK
+	case "features":
+		// craft features predates craft list.
+		return runList(append([]string{"features"}, args[1:]...))
 	}
 
-	gen := make([]string, 0)
+	// Flags without a command are the flags of new, as in the first
+	// releases of craft.
+	if strings.HasPrefix(args[0], "-") {
+		return runNew(args)
+	}
 
-	for g := range allGenerators {
-		gen = append(gen, g)
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
 	}
 
-	files, err := manager.Generate(context.Background(), data, gen...)
-	if err != nil {
-		fmt.Println("failed to generate files: %w", err)
-		os.Exit(1)
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+	usage()
+
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: craft <command> [flags]\n\nCommands:\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
 
-	createdDirs := make(map[string]bool)
-	createdFiles := []string{}
+	fmt.Fprintf(os.Stderr, "\nRun craft help <command> for the flags of a command.\n")
+	fmt.Fprintf(os.Stderr, "\nExit codes: %d success, %d failure, %d usage error, %d interrupted.\n", exitOK, exitFailure, exitUsage, exitInterrupted)
+}
 
-	defer func() {
-		if err != nil {
-			for _, file := range createdFiles {
-				os.Remove(file)
-			}
-			for dir := range createdDirs {
-				os.RemoveAll(dir)
-			}
+// newFlagSet creates the flag set of a command, printing its usage, summary
+// and flags on -h.
+func newFlagSet(name, args, summary string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: craft %s %s\n\n%s\n\nFlags:\n", name, args, summary)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// parseFlags parses args with flags. When it returns false, the command
+// must stop and exit with the returned code.
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
 		}
-	}()
-
-	for k, v := range files {
-		fullPath := filepath.Join(data.ProjectName, k)
-
-		if strings.Contains(fullPath, "/") {
-			dir := filepath.Dir(fullPath)
-			if _, err := os.Stat(dir); os.IsNotExist(err) {
-				if err := os.MkdirAll(dir, 0755); err != nil {
-					fmt.Printf("Failed to create directory %s: %v\n", dir, err)
-					os.Exit(1)
-				}
-				createdDirs[dir] = true
+
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
+
+// usageError reports a misuse of a command and returns exitUsage.
+func usageError(flags *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Fprintf(flags.Output(), format+"\n\n", args...)
+	flags.Usage()
+
+	return exitUsage
+}
+
+// failure reports an error and returns exitFailure.
+func failure(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+
+	return exitFailure
+}
+
+// signalContext returns a context cancelled on SIGINT and SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// sourceFlags are the flags of the commands loading templates and plugins.
+type sourceFlags struct {
+	templatesDir *string
+	pluginsDir   *string
+}
+
+func addSourceFlags(flags *flag.FlagSet) *sourceFlags {
+	return &sourceFlags{
+		templatesDir: flags.String("templates-dir", "", "Comma-separated list of directories with templates overriding the embedded ones"),
+		pluginsDir:   flags.String("plugins-dir", "", "Directory searched for craft-gen-* generator plugins before $PATH"),
+	}
+}
+
+// manager loads the templates and generators selected by the flags. The
+// plugins are described within ctx.
+func (f *sourceFlags) manager(ctx context.Context) (*craft.Manager, error) {
+	layers, err := templateLayers(*f.templatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	return &craft.Manager{
+		Registry: newRegistry(ctx, *f.pluginsDir),
+		Options: craft.Options{
+			Templates: layers,
+			Logger:    log.New(os.Stderr, "", 0),
+		},
+	}, nil
+}
+
+// templateLayers stacks the template directories given on the command line,
+// then $XDG_CONFIG_HOME/craft/templates when it exists, on top of the
+// embedded templates.
+func templateLayers(dirs string) (craft.LayeredFS, error) {
+	var layers craft.LayeredFS
+
+	if dirs != "" {
+		for _, dir := range strings.Split(dirs, ",") {
+			if _, err := os.Stat(dir); err != nil {
+				return nil, fmt.Errorf("invalid templates directory: %w", err)
 			}
+
+			layers = append(layers, craft.Layer{Name: dir, FS: os.DirFS(dir)})
 		}
+	}
 
-		if err := os.WriteFile(fullPath, v, 0644); err != nil {
-			fmt.Printf("Failed to write file %s: %v\n", fullPath, err)
-			os.Exit(1)
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dir := filepath.Join(configDir, "craft", "templates")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			layers = append(layers, craft.Layer{Name: dir, FS: os.DirFS(dir)})
 		}
+	}
 
-		createdFiles = append(createdFiles, fullPath)
+	embedded, err := fs.Sub(templates, "templates")
+	if err != nil {
+		return nil, err
 	}
-}
 
-func parseFlags() craft.Data {
-	name := flag.String("name", "", "Name of the project")
-	module := flag.String("module", "", "Go module prefix (e.g., github.com/username)")
-	bins := flag.String("binaries", "", "Comma-separated list of binaries to generate")
-	include := flag.String("include", "", "Comma-separated list of features to include (server,cli,proto)")
-	license := flag.String("license", "mit", "License type (mit, apache2, gpl3, bsd3, agpl3, lgpl3, mpl2, unlicense, custom)")
-	goVer := flag.String("go", "1.21", "Go version to use")
-	author := flag.String("author", "", "Author name for copyright")
-	configDirs := flag.String("config-dirs", "", "Comma-separated list of config directories")
-	configFile := flag.String("config-file", "config.yml", "Default config filename")
-	configFormat := flag.String("config-format", "yml", "Default config format (yml, yaml, json, toml)")
-	envPrefix := flag.String("env-prefix", "", "Environment variable prefix (defaults to project name)")
-	cliFramework := flag.String("cli", "cobra", "CLI framework to use (cobra or urfave)")
+	return append(layers, craft.Layer{Name: embeddedLayer, FS: embedded}), nil
+}
 
-	flag.Parse()
+// newRegistry registers the craft-gen-* plugins found in dir,
+// $XDG_CONFIG_HOME/craft/plugins and $PATH next to the built-in generators.
+// Plugins that fail to load, or clash with another generator, are skipped
+// with a warning.
+func newRegistry(ctx context.Context, dir string) *craft.Registry {
+	var dirs []string
 
-	if *name == "" || *module == "" {
-		fmt.Println("Please provide project name and module prefix")
-		flag.Usage()
-		os.Exit(1)
+	if dir != "" {
+		dirs = append(dirs, dir)
 	}
 
-	defaultConfigDirs := []string{
-		fmt.Sprintf("/etc/%s", *name),
-		fmt.Sprintf("$HOME/.config/%s", *name),
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "craft", "plugins"))
 	}
 
-	configDirsList := defaultConfigDirs
-	if *configDirs != "" {
-		configDirsList = strings.Split(*configDirs, ",")
+	plugins, errs := craft.DiscoverPlugins(ctx, dirs...)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %v\n", err)
 	}
 
-	prefix := *envPrefix
-	if prefix == "" {
-		prefix = strings.ToUpper(strings.Replace(*name, "-", "_", -1))
-	}
+	registry := craft.DefaultRegistry()
 
-	binaries := []string{}
-	if *bins != "" {
-		binaries = strings.Split(*bins, ",")
+	for _, p := range plugins {
+		if err := registry.Register(p); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping plugin %s: %v\n", p.Path, err)
+		}
 	}
 
-	includes := []string{}
-	if *include != "" {
-		includes = strings.Split(*include, ",")
+	return registry
+}
+
+// printOverrides reports the templates resolved from a layer other than the
+// embedded one.
+func printOverrides(files map[string]craft.File) {
+	seen := make(map[string]bool)
+
+	for _, f := range files {
+		for i, layer := range f.Layers {
+			tmpl := f.Templates[i]
+			if layer == embeddedLayer || seen[tmpl] {
+				continue
+			}
+
+			seen[tmpl] = true
+			fmt.Fprintf(os.Stderr, "Using %s from %s\n", tmpl, layer)
+		}
 	}
+}
 
-	return craft.Data{
-		ProjectName:  *name,
-		ModulePrefix: *module,
-		Binaries:     binaries,
-		Includes:     includes,
-		License:      *license,
-		GoVersion:    *goVer,
-		Author:       *author,
-		ConfigDirs:   configDirsList,
-		ConfigFile:   *configFile,
-		ConfigFormat: *configFormat,
-		EnvPrefix:    prefix,
-		CLI: craft.CLI{
-			Framework: *cliFramework,
-		},
-		Module:      *module,
-		AppName:     *name,
-		Description: *name,
-		Commands:    []string{},
+// splitList splits a comma-separated list, trimming the items and dropping
+// the empty ones.
+func splitList(s string) []string {
+	var list []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
+
+	return list
 }
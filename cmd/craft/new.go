@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runNew generates a new project, or regenerates an existing one, from the
+// command line flags.
+func runNew(args []string) int {
+	flags := newFlagSet("new", "[flags]", "Generate a new project in a directory named after it.")
+	project := addProjectFlags(flags)
+	sources := addSourceFlags(flags)
+	dryRun := flags.Bool("dry-run", false, "Print the files that would be written without writing them")
+	diff := flags.Bool("diff", false, "Print a unified diff against the existing project without writing it")
+	output := flags.String("output", "", "Write the project to a .tar.gz, .tgz or .zip archive instead of a directory, or to stdout as txtar with -")
+	onConflict := flags.String("on-conflict", string(craft.ConflictFail), "What to do with existing files that differ from the generated ones: skip, overwrite, backup, fail or prompt")
+	only := flags.String("only", "", "Comma-separated list of generators to run, see craft list generators (defaults to all)")
+	skip := flags.String("skip", "", "Comma-separated list of generators not to run")
+	interactive := flags.Bool("interactive", false, "Prompt for the project settings, reading the answers from stdin (the default on a terminal when -name or -module is missing)")
+
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	spec, err := project.spec(craft.DefaultSpec())
+	if err != nil {
+		return failure("Failed to load spec: %v", err)
+	}
+
+	// Regenerating a project starts from the data recorded in its lock, so
+	// that only the settings given on the command line change and the
+	// commands added since are kept.
+	if spec.Name != "" {
+		if existing, err := craft.ReadLock(spec.Name); err == nil {
+			if spec, err = project.spec(existing.Data.Spec()); err != nil {
+				return failure("Failed to load spec: %v", err)
+			}
+		}
+	}
+
+	if *interactive || (spec.Name == "" || spec.Module == "") && isTerminal(os.Stdin) {
+		if spec, err = newWizard().run(spec); err != nil {
+			if errors.Is(err, errAborted) {
+				fmt.Fprintln(os.Stderr, "Aborted, no files were written")
+				return exitFailure
+			}
+
+			return failure("Failed to read project settings: %v", err)
+		}
+	}
+
+	if spec.Name == "" || spec.Module == "" {
+		return usageError(flags, "Please provide project name and module prefix")
+	}
+
+	data := spec.Data()
+
+	policy, err := craft.ParseConflictPolicy(*onConflict)
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	manager, err := sources.manager(ctx)
+	if err != nil {
+		return failure("Failed to load generators: %v", err)
+	}
+
+	gen, err := manager.Registry.Select(splitList(*only), splitList(*skip))
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+
+	rendered, err := manager.Render(ctx, data, gen...)
+	if err != nil {
+		return failure("Failed to generate files: %v", err)
+	}
+
+	lock := craft.NewLock(data, rendered)
+	lock.Generators = gen
+
+	// The files of an existing project still as craft generated them are
+	// rewritten, like craft add does, and the conflict policy only applies
+	// to the others.
+	var updated map[string]craft.File
+
+	if existing, err := craft.ReadLock(data.ProjectName); err == nil {
+		updated = pristineChanges(data.ProjectName, existing, rendered)
+
+		// Running some of the generators into an existing project keeps
+		// track of the files of the others.
+		if *only != "" || *skip != "" {
+			lock = mergeLock(existing, data, gen, rendered, manager.Registry.Names())
+		}
+	}
+
+	files, err := withLock(rendered, lock, nil)
+	if err != nil {
+		return failure("Failed to create lock file: %v", err)
+	}
+
+	if *dryRun || *diff {
+		changes, err := craft.Plan(data.ProjectName, files)
+		if err != nil {
+			return failure("Failed to plan changes: %v", err)
+		}
+
+		for _, change := range changes {
+			if *diff {
+				fmt.Print(change.Diff())
+				continue
+			}
+
+			fmt.Printf("%-10s %s\n", change.Kind, change.Path)
+		}
+
+		printOverrides(rendered)
+
+		return exitOK
+	}
+
+	printOverrides(rendered)
+
+	if *output != "" {
+		if files, err = withLock(rendered, lock, rendered); err != nil {
+			return failure("Failed to create lock file: %v", err)
+		}
+
+		if err := writeArchive(ctx, *output, data.ProjectName, files); err != nil {
+			return writeFailed(err)
+		}
+
+		return exitOK
+	}
+
+	pending := make(map[string]craft.File, len(rendered))
+	for path, f := range rendered {
+		if _, ok := updated[path]; !ok {
+			pending[path] = f
+		}
+	}
+
+	// The lock file and the merge bases are craft's own and always written.
+	files, results, err := craft.ResolveConflicts(data.ProjectName, pending, policy, promptConflict)
+	if err != nil {
+		return failure("Failed to write files: %v", err)
+	}
+
+	for path, f := range updated {
+		files[path] = f
+		results = append(results, craft.WriteResult{Path: path, Action: craft.WriteOverwritten})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	if files, err = withLock(files, lock, rendered); err != nil {
+		return failure("Failed to create lock file: %v", err)
+	}
+
+	if err := writeFiles(ctx, data.ProjectName, files); err != nil {
+		return writeFailed(err)
+	}
+
+	printSummary(results)
+
+	return exitOK
+}
+
+// withLock returns files along with the lock file and the merge bases of
+// the generated files it records, bases, under craft.BaseDir.
+func withLock(rendered map[string]craft.File, lock *craft.Lock, bases map[string]craft.File) (map[string]craft.File, error) {
+	content, err := lock.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]craft.File, len(rendered)+len(bases)+1)
+	for k, v := range rendered {
+		files[k] = v
+	}
+
+	for k, v := range craft.BaseFiles(bases) {
+		files[k] = v
+	}
+
+	files[craft.LockFileName] = craft.File{
+		Path:    craft.LockFileName,
+		Content: content,
+		Mode:    craft.DefaultFileMode,
+	}
+
+	return files, nil
+}
+
+// pristineChanges returns the files of rendered that change a file of the
+// project in dir still as recorded in lock.
+func pristineChanges(dir string, lock *craft.Lock, rendered map[string]craft.File) map[string]craft.File {
+	changed := make(map[string]craft.File)
+
+	for path, f := range rendered {
+		locked, ok := lock.File(path)
+		if !ok || locked.Hash == craft.Hash(f.Content) || !pristine(dir, lock, path) {
+			continue
+		}
+
+		f.CreateOnly = false
+		changed[path] = f
+	}
+
+	return changed
+}
+
+// mergeLock records the files and generators of a partial run in the lock
+// of an existing project, where no generators stand for all of them.
+func mergeLock(existing *craft.Lock, data craft.Data, gen []string, rendered map[string]craft.File, all []string) *craft.Lock {
+	generators := existing.Generators
+	if len(generators) == 0 {
+		generators = all
+	}
+
+	for _, name := range gen {
+		if !contains(generators, name) {
+			generators = append(generators, name)
+		}
+	}
+
+	existing.Version = craft.Version
+	existing.Data = data
+	existing.Generators = generators
+	existing.Record(rendered)
+
+	return existing
+}
+
+// projectFlags are the flags describing a new project. They override the
+// values of the spec given with -f, or recorded in the lock of the project
+// being regenerated.
+type projectFlags struct {
+	flags        *flag.FlagSet
+	file         *string
+	name         *string
+	module       *string
+	binaries     *string
+	include      *string
+	license      *string
+	goVersion    *string
+	author       *string
+	configDirs   *string
+	configFile   *string
+	configFormat *string
+	envPrefix    *string
+	cli          *string
+}
+
+func addProjectFlags(flags *flag.FlagSet) *projectFlags {
+	defaults := craft.DefaultSpec()
+
+	return &projectFlags{
+		flags:        flags,
+		file:         flags.String("f", "", "Project spec file (YAML, JSON or TOML), see craft spec init"),
+		name:         flags.String("name", "", "Name of the project"),
+		module:       flags.String("module", "", "Go module prefix (e.g., github.com/username)"),
+		binaries:     flags.String("binaries", "", "Comma-separated list of binaries to generate (defaults to the project name)"),
+		include:      flags.String("include", "", "Comma-separated list of features to include, or none, see craft list features (defaults to all)"),
+		license:      flags.String("license", defaults.License, "License type, see craft list licenses"),
+		goVersion:    flags.String("go", defaults.GoVersion, "Go version to use"),
+		author:       flags.String("author", "", "Author name for copyright"),
+		configDirs:   flags.String("config-dirs", "", "Comma-separated list of config directories"),
+		configFile:   flags.String("config-file", defaults.ConfigFile, "Default config filename"),
+		configFormat: flags.String("config-format", defaults.ConfigFormat, "Default config format (yml, yaml, json, toml)"),
+		envPrefix:    flags.String("env-prefix", "", "Environment variable prefix (defaults to project name)"),
+		cli:          flags.String("cli", defaults.CLI, "CLI framework to use (cobra or urfave)"),
+	}
+}
+
+// spec returns base, replaced by the spec file given with -f if any, with
+// the flags set on the command line applied on top. The commands of base
+// are kept when the spec file lists none.
+func (f *projectFlags) spec(base craft.Spec) (craft.Spec, error) {
+	spec := base
+
+	if *f.file != "" {
+		var err error
+		if spec, err = craft.LoadSpec(*f.file); err != nil {
+			return craft.Spec{}, err
+		}
+
+		if len(spec.Commands) == 0 {
+			spec.Commands = base.Commands
+		}
+	}
+
+	list := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+
+		return strings.Split(s, ",")
+	}
+
+	f.flags.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "name":
+			spec.Name = *f.name
+		case "module":
+			spec.Module = *f.module
+		case "binaries":
+			spec.Binaries = list(*f.binaries)
+		case "include":
+			spec.Includes = list(*f.include)
+		case "license":
+			spec.License = *f.license
+		case "go":
+			spec.GoVersion = *f.goVersion
+		case "author":
+			spec.Author = *f.author
+		case "config-dirs":
+			spec.ConfigDirs = list(*f.configDirs)
+		case "config-file":
+			spec.ConfigFile = *f.configFile
+		case "config-format":
+			spec.ConfigFormat = *f.configFormat
+		case "env-prefix":
+			spec.EnvPrefix = *f.envPrefix
+		case "cli":
+			spec.CLI = *f.cli
+		}
+	})
+
+	return spec, nil
+}
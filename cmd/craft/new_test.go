@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// projectSpec parses args as the project flags of craft new and returns
+// the resulting spec on top of base.
+func projectSpec(t *testing.T, base craft.Spec, args ...string) (craft.Spec, error) {
+	t.Helper()
+
+	flags := newFlagSet("new", "[flags]", "")
+	project := addProjectFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	return project.spec(base)
+}
+
+func TestProjectFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "craft.yaml")
+
+	content := "name: example\nmodule: github.com/example/example\nlicense: apache-2.0\nbinaries: [examplectl]\nincludes: [docker]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	base := craft.DefaultSpec()
+	base.Name = "locked"
+	base.Commands = []craft.Command{{Binary: "examplectl", Name: "migrate"}}
+
+	tests := []struct {
+		name   string
+		args   []string
+		modify func(s *craft.Spec)
+	}{
+		{
+			// Flags left to their default do not replace the spec.
+			name: "spec file",
+			args: []string{"-f", path},
+		},
+		{
+			name: "flags override the spec file",
+			args: []string{"-f", path, "-name", "other", "-license", "mit", "-include", "k8s,helm", "-cli", "urfave", "-go", "1.22"},
+			modify: func(s *craft.Spec) {
+				s.Name = "other"
+				s.License = "mit"
+				s.Includes = []string{"k8s", "helm"}
+				s.CLI = "urfave"
+				s.GoVersion = "1.22"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectSpec(t, base, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := craft.DefaultSpec()
+			want.Name = "example"
+			want.Module = "github.com/example/example"
+			want.License = "apache-2.0"
+			want.Binaries = []string{"examplectl"}
+			want.Includes = []string{"docker"}
+			want.Commands = base.Commands
+
+			if tt.modify != nil {
+				tt.modify(&want)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestProjectFlagsWithoutSpec(t *testing.T) {
+	base := craft.DefaultSpec()
+	base.Name = "locked"
+	base.License = "apache-2.0"
+
+	got, err := projectSpec(t, base, "-author", "Jane Doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := base
+	want.Author = "Jane Doe"
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestProjectFlagsInvalidSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "craft.yaml")
+	if err := os.WriteFile(path, []byte("name: example\nlicence: mit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := projectSpec(t, craft.DefaultSpec(), "-f", path); err == nil {
+		t.Error("got no error from a spec with an unknown setting")
+	}
+}
+
+func TestNewOnly(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if code := run([]string{"new", "-name", "example", "-module", "github.com/example/example", "-include", "none", "-license", "mit"}); code != exitOK {
+		t.Fatalf("got exit code %d generating the project", code)
+	}
+
+	// LICENSE is still as generated, so that it is rewritten despite the
+	// default conflict policy.
+	if code := run([]string{"new", "-name", "example", "-only", "license", "-license", "apache-2.0"}); code != exitOK {
+		t.Fatalf("got exit code %d changing the license", code)
+	}
+
+	content, err := os.ReadFile(filepath.Join("example", "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), "Apache License") {
+		t.Errorf("got LICENSE\n%s\nwant the Apache license", content)
+	}
+
+	if err := os.WriteFile(filepath.Join("example", "LICENSE"), []byte("Edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := run([]string{"new", "-name", "example", "-only", "license", "-license", "mit"}); code != exitFailure {
+		t.Errorf("got exit code %d overwriting an edited LICENSE, want %d", code, exitFailure)
+	}
+}
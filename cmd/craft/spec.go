@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runSpec runs the craft spec subcommands.
+func runSpec(args []string) int {
+	if len(args) == 0 || args[0] != "init" {
+		fmt.Fprintln(os.Stderr, "Usage: craft spec init [flags]")
+		return exitUsage
+	}
+
+	flags := newFlagSet("spec init", "[flags]", "Write a commented starter project spec, to generate the project with\ncraft new -f.")
+	output := flags.String("o", "craft.yaml", "File to write the spec to, - for stdout")
+	name := flags.String("name", "", "Name of the project (defaults to the name of the current directory)")
+	module := flags.String("module", "", "Go module path")
+	force := flags.Bool("force", false, "Overwrite an existing spec")
+
+	if code, ok := parseFlags(flags, args[1:]); !ok {
+		return code
+	}
+
+	if *name == "" {
+		if wd, err := os.Getwd(); err == nil {
+			*name = filepath.Base(wd)
+		}
+	}
+
+	content := craft.StarterSpec(*name, *module)
+
+	if *output == "-" {
+		os.Stdout.Write(content)
+		return exitOK
+	}
+
+	if _, err := os.Stat(*output); !*force && !errors.Is(err, fs.ErrNotExist) {
+		return failure("%s already exists, use -force to overwrite it", *output)
+	}
+
+	if err := os.WriteFile(*output, content, craft.DefaultFileMode); err != nil {
+		return failure("Failed to write spec: %v", err)
+	}
+
+	fmt.Printf("Wrote %s, generate the project with craft new -f %s\n", *output, *output)
+
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runTemplates runs the craft templates subcommands.
+func runTemplates(args []string) int {
+	if len(args) == 0 || args[0] != "lint" {
+		fmt.Fprintln(os.Stderr, "Usage: craft templates lint [flags]")
+		return exitUsage
+	}
+
+	flags := newFlagSet("templates lint", "[flags]", "Report templates referenced by generators but missing, templates no generator\nuses and templates that do not parse. Exits with 1 when issues are found.")
+	sources := addSourceFlags(flags)
+
+	if code, ok := parseFlags(flags, args[1:]); !ok {
+		return code
+	}
+
+	manager, err := sources.manager(context.Background())
+	if err != nil {
+		return failure("Failed to load generators: %v", err)
+	}
+
+	issues, err := craft.Lint(manager.Options.Templates, manager.Registry)
+	if err != nil {
+		return failure("Failed to lint templates: %v", err)
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("%-8s %s\n", issue.Kind, issue)
+	}
+
+	if len(issues) > 0 {
+		return failure("%d issue(s) found", len(issues))
+	}
+
+	return exitOK
+}
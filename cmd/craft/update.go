@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// runUpdate regenerates the project in --dir with the data recorded in its
+// lock file and merges the result with the files on disk.
+func runUpdate(args []string) int {
+	flags := newFlagSet("update", "[flags]", "Regenerate a project with the data recorded in its lock file and merge the\nresult with the local changes. Exits with 1 when conflicts are left to resolve.")
+	dir := flags.String("dir", ".", "Directory of the project to update")
+	dryRun := flags.Bool("dry-run", false, "Print what would be updated without writing anything")
+	sources := addSourceFlags(flags)
+
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	lock, err := craft.ReadLock(*dir)
+	if err != nil {
+		return failure("Failed to load project: %v", err)
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	manager, err := sources.manager(ctx)
+	if err != nil {
+		return failure("Failed to load generators: %v", err)
+	}
+
+	gen := lock.Generators
+	if len(gen) == 0 {
+		gen = manager.Registry.Names()
+	}
+
+	rendered, err := manager.Render(ctx, lock.Data, gen...)
+	if err != nil {
+		return failure("Failed to generate files: %v", err)
+	}
+
+	results, err := craft.Update(*dir, lock, rendered)
+	if err != nil {
+		return failure("Failed to update project: %v", err)
+	}
+
+	conflicts := 0
+	for _, result := range results {
+		if result.Action != craft.UpdateUnchanged {
+			fmt.Printf("%-10s %s\n", result.Action, result.Path)
+		}
+
+		if result.Action == craft.UpdateConflict {
+			conflicts++
+		}
+	}
+
+	if *dryRun {
+		return exitOK
+	}
+
+	newLock := craft.NewLock(lock.Data, rendered)
+	newLock.Generators = lock.Generators
+
+	files, err := withLock(nil, newLock, rendered)
+	if err != nil {
+		return failure("Failed to create lock file: %v", err)
+	}
+
+	for _, result := range results {
+		if result.Content == nil {
+			continue
+		}
+
+		files[result.Path] = craft.File{
+			Path:    result.Path,
+			Content: result.Content,
+			Mode:    result.Mode,
+		}
+	}
+
+	if err := writeFiles(ctx, *dir, files); err != nil {
+		return writeFailed(err)
+	}
+
+	if conflicts > 0 {
+		return failure("%d file(s) have conflicts, resolve the conflict markers before committing", conflicts)
+	}
+
+	return exitOK
+}
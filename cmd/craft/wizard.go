@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// ciProviders are the features offered as CI provider by the wizard, along
+// with none.
+var ciProviders = []string{"github", "gitlab"}
+
+// errAborted is returned by the wizard when the summary is not confirmed.
+var errAborted = errors.New("aborted")
+
+// wizard asks for the settings of a new project. Answers are read line by
+// line, so that it can be driven through stdin as well as from a terminal.
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newWizard() *wizard {
+	return &wizard{in: stdin, out: os.Stderr}
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or a file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// run asks for every setting, offering the values of spec as defaults, then
+// shows a summary and returns errAborted unless it is confirmed.
+func (w *wizard) run(spec craft.Spec) (craft.Spec, error) {
+	fmt.Fprintln(w.out, "Answer the questions below, or press enter to keep the value in brackets.")
+
+	var err error
+
+	if spec.Name, err = w.ask("Project name", spec.Name, craft.ValidateProjectName); err != nil {
+		return craft.Spec{}, err
+	}
+
+	if spec.Module, err = w.ask("Go module path", spec.Module, craft.ValidateModulePath); err != nil {
+		return craft.Spec{}, err
+	}
+
+	binaries := spec.Binaries
+	if len(binaries) == 0 {
+		binaries = []string{spec.Name}
+	}
+
+	answer, err := w.ask("Binaries (comma-separated)", strings.Join(binaries, ","), func(s string) error {
+		return craft.ValidateBinaries(splitList(s))
+	})
+	if err != nil {
+		return craft.Spec{}, err
+	}
+
+	spec.Binaries = splitList(answer)
+
+	if spec.CLI, err = w.choose("CLI framework", craft.CLIFrameworks, spec.CLI); err != nil {
+		return craft.Spec{}, err
+	}
+
+	licenses := make([]string, 0, len(craft.Licenses))
+	for name := range craft.Licenses {
+		licenses = append(licenses, name)
+	}
+	sort.Strings(licenses)
+
+	if spec.License, err = w.choose("License", licenses, spec.License); err != nil {
+		return craft.Spec{}, err
+	}
+
+	if spec.Includes, err = w.features(spec.Includes); err != nil {
+		return craft.Spec{}, err
+	}
+
+	w.summary(spec)
+
+	ok, err := w.confirm(fmt.Sprintf("Generate the project in %s?", spec.Name))
+	if err != nil {
+		return craft.Spec{}, err
+	}
+
+	if !ok {
+		return craft.Spec{}, errAborted
+	}
+
+	return spec, nil
+}
+
+// features asks for the features and the CI provider, returning the
+// resulting includes. Every feature but the CI providers not chosen is
+// included for all.
+func (w *wizard) features(includes []string) ([]string, error) {
+	var features, available []string
+
+	ci := "none"
+
+	for _, name := range includes {
+		if name == craft.NoFeatures {
+			continue
+		}
+
+		if contains(ciProviders, name) {
+			ci = name
+			continue
+		}
+
+		features = append(features, name)
+	}
+
+	if len(includes) == 0 {
+		ci = ciProviders[0]
+	}
+
+	for _, f := range craft.Features {
+		if !contains(ciProviders, f.Name) {
+			available = append(available, f.Name)
+		}
+	}
+
+	def := "all"
+	switch {
+	case len(features) > 0:
+		def = strings.Join(features, ",")
+	case len(includes) > 0:
+		def = "none"
+	}
+
+	fmt.Fprintf(w.out, "Available features: %s\n", strings.Join(available, ", "))
+
+	answer, err := w.ask("Features (comma-separated, all or none)", def, func(s string) error {
+		if s == "all" || s == "none" {
+			return nil
+		}
+
+		for _, name := range splitList(s) {
+			if !contains(available, name) {
+				return fmt.Errorf("unknown feature %q", name)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch answer {
+	case "all":
+		features = available
+	case "none":
+		features = nil
+	default:
+		features = splitList(answer)
+	}
+
+	if ci, err = w.choose("CI provider", append(append([]string{}, ciProviders...), "none"), ci); err != nil {
+		return nil, err
+	}
+
+	result := append([]string{}, features...)
+	if ci != "none" {
+		result = append(result, ci)
+	}
+
+	// An empty list would include every feature.
+	if len(result) == 0 {
+		result = []string{craft.NoFeatures}
+	}
+
+	return result, nil
+}
+
+// summary prints the settings the project is about to be generated with.
+func (w *wizard) summary(spec craft.Spec) {
+	features := strings.Join(spec.Includes, ", ")
+	if features == "" {
+		features = "none"
+	}
+
+	fmt.Fprintf(w.out, "\nProject:       %s\n", spec.Name)
+	fmt.Fprintf(w.out, "Module:        %s\n", spec.Module)
+	fmt.Fprintf(w.out, "Binaries:      %s\n", strings.Join(spec.Binaries, ", "))
+	fmt.Fprintf(w.out, "CLI framework: %s\n", spec.CLI)
+	fmt.Fprintf(w.out, "License:       %s\n", spec.License)
+	fmt.Fprintf(w.out, "Features:      %s\n\n", features)
+}
+
+// ask prints question along with def and returns the answer, def when it
+// is empty. Answers rejected by validate are asked again.
+func (w *wizard) ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+
+		answer, err := w.in.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && answer != "") {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = def
+		}
+
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(w.out, "  %v\n", err)
+				continue
+			}
+		}
+
+		return answer, nil
+	}
+}
+
+// choose asks for one of choices.
+func (w *wizard) choose(question string, choices []string, def string) (string, error) {
+	return w.ask(fmt.Sprintf("%s (%s)", question, strings.Join(choices, ", ")), def, func(s string) error {
+		if !contains(choices, s) {
+			return fmt.Errorf("expected one of %s", strings.Join(choices, ", "))
+		}
+
+		return nil
+	})
+}
+
+// confirm asks a yes or no question, defaulting to yes.
+func (w *wizard) confirm(question string) (bool, error) {
+	answer, err := w.ask(question+" [Y/n]", "", func(s string) error {
+		switch strings.ToLower(s) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+
+		return fmt.Errorf("expected y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "n", "no":
+		return false, nil
+	}
+
+	return true, nil
+}
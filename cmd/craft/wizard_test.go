@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func runWizard(spec craft.Spec, answers ...string) (craft.Spec, string, error) {
+	var out bytes.Buffer
+
+	w := &wizard{
+		in:  bufio.NewReader(strings.NewReader(strings.Join(answers, "\n") + "\n")),
+		out: &out,
+	}
+
+	spec, err := w.run(spec)
+
+	return spec, out.String(), err
+}
+
+func TestWizardDefaults(t *testing.T) {
+	spec := craft.DefaultSpec()
+	spec.Name = "demo"
+	spec.Module = "example.com/demo"
+
+	// Every question answered with enter.
+	got, _, err := runWizard(spec, "", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var includes []string
+	for _, f := range craft.Features {
+		if !contains(ciProviders, f.Name) {
+			includes = append(includes, f.Name)
+		}
+	}
+
+	want := spec
+	want.Binaries = []string{"demo"}
+	want.Includes = append(includes, "github")
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWizardAnswers(t *testing.T) {
+	got, out, err := runWizard(craft.DefaultSpec(),
+		"Bad Name!", "demo", // name
+		"example.com/bad path", "example.com/demo", // module
+		"demo,demo", "democtl,demod", // binaries
+		"gin", "urfave", // CLI framework
+		"wtfpl", "apache-2.0", // license
+		"docker,nope", "docker, postgres", // features
+		"travis", "none", // CI provider
+		"maybe", "y", // confirmation
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := craft.DefaultSpec()
+	want.Name = "demo"
+	want.Module = "example.com/demo"
+	want.Binaries = []string{"democtl", "demod"}
+	want.CLI = "urfave"
+	want.License = "apache-2.0"
+	want.Includes = []string{"docker", "postgres"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Every invalid answer is reported and the question asked again.
+	for _, question := range []string{
+		"Project name",
+		"Go module path",
+		"Binaries (comma-separated)",
+		"CLI framework (",
+		"License (",
+		"Features (",
+		"CI provider (",
+		"Generate the project in demo?",
+	} {
+		if n := strings.Count(out, question); n != 2 {
+			t.Errorf("%q asked %d times, want 2", question, n)
+		}
+	}
+
+	for _, msg := range []string{
+		"invalid project name",
+		"invalid module path",
+		`unknown feature "nope"`,
+		"expected one of cobra, urfave",
+		"expected y or n",
+	} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("output does not report %q:\n%s", msg, out)
+		}
+	}
+}
+
+func TestWizardAborted(t *testing.T) {
+	spec := craft.DefaultSpec()
+	spec.Name = "demo"
+	spec.Module = "example.com/demo"
+
+	if _, _, err := runWizard(spec, "", "", "", "", "", "none", "", "n"); !errors.Is(err, errAborted) {
+		t.Errorf("got error %v, want %v", err, errAborted)
+	}
+}
+
+func TestWizardEOF(t *testing.T) {
+	if _, _, err := runWizard(craft.DefaultSpec(), "demo"); err == nil || errors.Is(err, errAborted) {
+		t.Errorf("got error %v, want a read error", err)
+	}
+}
+
+func TestWizardNoFeatures(t *testing.T) {
+	spec := craft.DefaultSpec()
+	spec.Name = "demo"
+	spec.Module = "example.com/demo"
+
+	got, _, err := runWizard(spec, "", "", "", "", "", "none", "none", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{craft.NoFeatures}; !reflect.DeepEqual(got.Includes, want) {
+		t.Fatalf("got includes %v, want %v", got.Includes, want)
+	}
+
+	layers, err := templateLayers("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := craft.Manager{
+		Registry: craft.DefaultRegistry(),
+		Options:  craft.Options{Templates: layers},
+	}
+
+	files, err := manager.Render(context.Background(), got.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path := range files {
+		for _, optional := range []string{"cmd/", "docker/", "build/", ".github/", ".gitlab", "Taskfile", "dagger.cue", "scripts/tasks/docker.sh", "scripts/tasks/proto.sh"} {
+			if strings.HasPrefix(path, optional) {
+				t.Errorf("%s is generated without any feature", path)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// writeFiles writes files into dir in a single transaction: either every
+// file ends up on disk or, on failure or interruption, none does.
+func writeFiles(ctx context.Context, dir string, files map[string]craft.File) error {
+	tx, err := craft.Begin(dir)
+	if err != nil {
+		return err
+	}
+
+	return craft.WriteFiles(ctx, tx, files)
+}
+
+// writeArchive writes files under the root directory of an archive. The
+// format follows the extension of output, and - streams a txtar bundle to
+// stdout. The archive only appears once it is complete.
+func writeArchive(ctx context.Context, output, root string, files map[string]craft.File) error {
+	if output == "-" {
+		return craft.WriteFiles(ctx, craft.NewTxtarSink(os.Stdout, root), files)
+	}
+
+	var newSink func(io.Writer, string) craft.Sink
+
+	switch {
+	case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+		newSink = craft.NewTarGzSink
+	case strings.HasSuffix(output, ".zip"):
+		newSink = craft.NewZipSink
+	default:
+		return fmt.Errorf("unsupported output %s, expected a .tar.gz, .tgz or .zip file, or - for stdout", output)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := craft.WriteFiles(ctx, newSink(f, root), files); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	if err := os.Chmod(f.Name(), craft.DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	return os.Rename(f.Name(), output)
+}
+
+// writeFailed reports a failed write and returns the exit code.
+func writeFailed(err error) int {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted, no files were written")
+		return exitInterrupted
+	}
+
+	return failure("Failed to write files: %v", err)
+}
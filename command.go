@@ -2,16 +2,28 @@ package craft
 
 import (
 	"fmt"
+	"go/token"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/iancoleman/strcase"
 )
 
-func GenerateCommands(data Data) (map[string]RenderOptions, error) {
-	cliFrameworks := []string{"cobra", "urfave"}
+// CLIFrameworks lists the supported CLI frameworks.
+var CLIFrameworks = []string{"cobra", "urfave"}
 
-	if !contains(cliFrameworks, data.Framework) {
-		return nil, fmt.Errorf("invalid cli framework: %s", data.Framework)
+func validateFramework(data Data) error {
+	if !contains(CLIFrameworks, data.Framework) {
+		return fmt.Errorf("invalid cli framework: %s", data.Framework)
+	}
+
+	return nil
+}
+
+func GenerateCommands(data Data) (map[string]RenderOptions, error) {
+	if err := validateFramework(data); err != nil {
+		return nil, err
 	}
 
 	templates := map[string][]string{
@@ -20,17 +32,35 @@ func GenerateCommands(data Data) (map[string]RenderOptions, error) {
 		"server":  {"internal/commands/base.go.tmpl", fmt.Sprintf("internal/commands/%s_root.go.tmpl", data.Framework), fmt.Sprintf("internal/commands/%s_server.go.tmpl", data.Framework)},
 	}
 
+	// Commands gated on a feature of their own
+	features := map[string][]string{
+		"server": {"server"},
+	}
+
 	out := make(map[string]RenderOptions)
 
+	for _, cmd := range data.Commands {
+		out[cmd.File(data)] = RenderOptions{
+			Templates: []string{"internal/commands/base.go.tmpl", fmt.Sprintf("internal/commands/%s_command.go.tmpl", data.Framework)},
+			Execute:   "base",
+			Data: CommandOptions{
+				Data:    data,
+				Binary:  cmd.Binary,
+				Command: cmd,
+			},
+		}
+	}
+
 	if len(data.Binaries) == 1 {
 		for key, tmpl := range templates {
 			out[fmt.Sprintf("internal/commands/%s.go", key)] = RenderOptions{
 				Templates: tmpl,
+				Execute:   "base",
 				Data: CommandOptions{
-					Data:    data,
-					Binary:  data.Binaries[0],
-					Execute: "base",
+					Data:   data,
+					Binary: data.Binaries[0],
 				},
+				Features: features[key],
 			}
 		}
 		return out, nil
@@ -40,11 +70,12 @@ func GenerateCommands(data Data) (map[string]RenderOptions, error) {
 		for key, tmpl := range templates {
 			out[fmt.Sprintf("internal/commands/%s/%s.go", binary, key)] = RenderOptions{
 				Templates: tmpl,
+				Execute:   "base",
 				Data: CommandOptions{
-					Data:    data,
-					Binary:  binary,
-					Execute: "base",
+					Data:   data,
+					Binary: binary,
 				},
+				Features: features[key],
 			}
 		}
 	}
@@ -52,26 +83,22 @@ func GenerateCommands(data Data) (map[string]RenderOptions, error) {
 	for _, binary := range data.Binaries {
 		out[fmt.Sprintf("cmd/%s/main.go", binary)] = RenderOptions{
 			Templates: []string{"internal/commands/main.go.tmpl"},
+			Execute:   "main",
 			Data: CommandOptions{
-				Data:    data,
-				Binary:  binary,
-				Execute: "main",
+				Data:   data,
+				Binary: binary,
 			},
 		}
 	}
 
+	readme := fmt.Sprintf("internal/commands/readme_%s.md.tmpl", data.Framework)
+
 	if data.Binaries != nil {
 		for _, binary := range data.Binaries {
-			out[fmt.Sprintf("internal/commands/%s/README.md", binary)] = RenderOptions{
-				Templates: []string{fmt.Sprintf("internal/commands/readme_%s.md.tmpl", data.Framework)},
-				Data:      data,
-			}
+			out[fmt.Sprintf("internal/commands/%s/README.md", binary)] = renderOptions(data, readme)
 		}
 	} else {
-		out["internal/commands/README.md"] = RenderOptions{
-			Templates: []string{"internal/commands/readme_base.md.tmpl"},
-			Data:      data,
-		}
+		out["internal/commands/README.md"] = renderOptions(data, readme)
 	}
 
 	return out, nil
@@ -79,15 +106,233 @@ func GenerateCommands(data Data) (map[string]RenderOptions, error) {
 
 type CommandOptions struct {
 	Data
-	Binary  string
-	Execute string
+	Binary string
+	// Command is the command rendered by the command templates.
+	Command Command
 }
 
 func (cmd CommandOptions) PackageName() string {
 	return strings.ReplaceAll(strcase.ToKebab(cmd.Binary), "-", "")
 }
 
-type RenderOptionsWithExecute struct {
-	RenderOptions
-	Execute string
+// Subcommands returns the commands of the binary added under parent, the
+// path of a command, or under the root command when parent is empty.
+func (cmd CommandOptions) Subcommands(parent string) []Command {
+	var commands []Command
+
+	for _, c := range cmd.Commands {
+		if c.Binary == cmd.Binary && c.Parent == parent {
+			commands = append(commands, c)
+		}
+	}
+
+	return commands
+}
+
+// Command is a subcommand added to a binary, see craft add command.
+type Command struct {
+	Binary string `json:"binary" yaml:"binary" toml:"binary"`
+	// Parent is the path of the parent command, such as server or db
+	// migrate, or empty for a command of the root command.
+	Parent string        `json:"parent,omitempty" yaml:"parent,omitempty" toml:"parent,omitempty"`
+	Name   string        `json:"name" yaml:"name" toml:"name"`
+	Flags  []CommandFlag `json:"flags,omitempty" yaml:"flags,omitempty" toml:"flags,omitempty"`
+}
+
+// Path returns the words invoking the command after the binary name.
+func (c Command) Path() string {
+	if c.Parent == "" {
+		return c.Name
+	}
+
+	return c.Parent + " " + c.Name
+}
+
+// FuncName returns the name of the function building the command.
+func (c Command) FuncName() string {
+	return commandFunc(c.Path())
+}
+
+// ParentFuncName returns the name of the function building the parent
+// command, CmdRoot for a command of the root command.
+func (c Command) ParentFuncName() string {
+	if c.Parent == "" {
+		return "CmdRoot"
+	}
+
+	return commandFunc(c.Parent)
+}
+
+// File returns the path of the file holding the command in a project
+// generated from data.
+func (c Command) File(data Data) string {
+	return path.Join(CommandsDir(data, c.Binary), strcase.ToSnake(c.Path())+".go")
+}
+
+func commandFunc(path string) string {
+	return "Cmd" + strcase.ToCamel(strings.ReplaceAll(path, " ", "-"))
+}
+
+// CommandsDir returns the directory of the commands package of binary.
+func CommandsDir(data Data, binary string) string {
+	if len(data.Binaries) == 1 {
+		return "internal/commands"
+	}
+
+	return path.Join("internal/commands", binary)
+}
+
+// CommandFlagTypes lists the accepted types of command flags.
+var CommandFlagTypes = []string{"string", "int", "bool", "float", "duration"}
+
+// CommandFlag is a flag of a command added with craft add command.
+type CommandFlag struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	Type string `json:"type" yaml:"type" toml:"type"`
+}
+
+// ParseCommandFlags parses a comma-separated list of name:type flags, the
+// type defaulting to string.
+func ParseCommandFlags(s string) ([]CommandFlag, error) {
+	var flags []CommandFlag
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, typ, _ := strings.Cut(item, ":")
+		if typ == "" {
+			typ = "string"
+		}
+
+		if !contains(CommandFlagTypes, typ) {
+			return nil, fmt.Errorf("invalid type %q of flag %s, expected one of %s", typ, name, strings.Join(CommandFlagTypes, ", "))
+		}
+
+		flags = append(flags, CommandFlag{Name: name, Type: typ})
+	}
+
+	return flags, nil
+}
+
+// VarName returns the variable the flag is bound to.
+func (f CommandFlag) VarName() string {
+	return strcase.ToLowerCamel(f.Name)
+}
+
+// GoType returns the Go type of the flag value.
+func (f CommandFlag) GoType() string {
+	switch f.Type {
+	case "float":
+		return "float64"
+	case "duration":
+		return "time.Duration"
+	}
+
+	return f.Type
+}
+
+// Kind returns the name of the flag type in the CLI frameworks, as in
+// cobra's StringVar or urfave's StringFlag.
+func (f CommandFlag) Kind() string {
+	switch f.Type {
+	case "float":
+		return "Float64"
+	}
+
+	return strcase.ToCamel(f.Type)
+}
+
+// Zero returns the Go literal of the default value of the flag.
+func (f CommandFlag) Zero() string {
+	switch f.Type {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+
+	return "0"
+}
+
+var commandNameRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedFlagVars are the identifiers in scope of the generated command
+// functions, which flag variables must not shadow.
+var reservedFlagVars = []string{"ctx", "appCtx", "cmd", "args", "c"}
+
+// validateCommands checks that every command belongs to a binary, hangs off
+// an existing parent and does not clash with another command.
+func validateCommands(d Data) []error {
+	var errs []error
+
+	funcs := make(map[string]string)
+	paths := make(map[string]bool)
+
+	for _, binary := range d.Binaries {
+		funcs[binary+" CmdRoot"] = "the root command"
+		funcs[binary+" CmdVersion"] = "version"
+		funcs[binary+" CmdServer"] = "server"
+
+		if d.HasFeature("server") {
+			paths[binary+" server"] = true
+		}
+	}
+
+	for _, c := range d.Commands {
+		paths[c.Binary+" "+c.Path()] = true
+	}
+
+	for _, c := range d.Commands {
+		if !contains(d.Binaries, c.Binary) {
+			errs = append(errs, fmt.Errorf("command %s: unknown binary %q", c.Path(), c.Binary))
+			continue
+		}
+
+		if !commandNameRe.MatchString(c.Name) {
+			errs = append(errs, fmt.Errorf("invalid command name %q: use lower case letters, digits and dashes, starting with a letter", c.Name))
+			continue
+		}
+
+		if c.Parent != "" && !paths[c.Binary+" "+c.Parent] {
+			errs = append(errs, fmt.Errorf("command %s: parent command %q of %s does not exist", c.Path(), c.Parent, c.Binary))
+		}
+
+		key := c.Binary + " " + c.FuncName()
+		if prev, ok := funcs[key]; ok {
+			errs = append(errs, fmt.Errorf("command %s of %s clashes with %s", c.Path(), c.Binary, prev))
+			continue
+		}
+
+		funcs[key] = c.Path()
+
+		errs = append(errs, c.validateFlags()...)
+	}
+
+	return errs
+}
+
+func (c Command) validateFlags() []error {
+	var errs []error
+
+	seen := make(map[string]bool)
+
+	for _, f := range c.Flags {
+		switch {
+		case !commandNameRe.MatchString(f.Name):
+			errs = append(errs, fmt.Errorf("command %s: invalid flag name %q: use lower case letters, digits and dashes, starting with a letter", c.Path(), f.Name))
+		case !contains(CommandFlagTypes, f.Type):
+			errs = append(errs, fmt.Errorf("command %s: invalid type %q of flag %s, expected one of %s", c.Path(), f.Type, f.Name, strings.Join(CommandFlagTypes, ", ")))
+		case token.IsKeyword(f.VarName()), contains(reservedFlagVars, f.VarName()):
+			errs = append(errs, fmt.Errorf("command %s: flag name %q is reserved", c.Path(), f.Name))
+		case seen[f.Name]:
+			errs = append(errs, fmt.Errorf("command %s: duplicate flag %s", c.Path(), f.Name))
+		}
+
+		seen[f.Name] = true
+	}
+
+	return errs
 }
@@ -5,11 +5,19 @@ func GenerateCommonFiles(data Data) (map[string]RenderOptions, error) {
 		".gitignore":   renderOptions(data, "common/gitignore.tmpl"),
 		".env.example": renderOptions(data, "common/env.tmpl"),
 		"go.mod":       renderOptions(data, "common/go.mod.tmpl"),
-		"README.md":    renderOptions(data, "common/readme.md.tmpl"),
+		"README.md":    createOnlyOptions(data, "common/readme.md.tmpl"),
 		".air.toml":    renderOptions(data, "common/air.toml.tmpl"),
 	}, nil
 }
 
-func renderOptions(data interface{}, templates ...string) RenderOptions {
+func renderOptions(data TemplateData, templates ...string) RenderOptions {
 	return RenderOptions{Templates: templates, Data: data}
 }
+
+func executableOptions(data TemplateData, templates ...string) RenderOptions {
+	return RenderOptions{Templates: templates, Data: data, Mode: 0755}
+}
+
+func createOnlyOptions(data TemplateData, templates ...string) RenderOptions {
+	return RenderOptions{Templates: templates, Data: data, CreateOnly: true}
+}
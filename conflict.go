@@ -0,0 +1,191 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConflictPolicy decides what happens to a file of the target directory
+// whose content differs from the generated one.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing file.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing file.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictBackup replaces the existing file after copying it to a file
+	// with the BackupSuffix.
+	ConflictBackup ConflictPolicy = "backup"
+	// ConflictFail aborts without writing anything.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictPrompt asks what to do for every conflicting file.
+	ConflictPrompt ConflictPolicy = "prompt"
+)
+
+// ConflictPolicies lists the valid conflict policies.
+var ConflictPolicies = []ConflictPolicy{ConflictSkip, ConflictOverwrite, ConflictBackup, ConflictFail, ConflictPrompt}
+
+// BackupSuffix is appended to the path of the copies made by ConflictBackup.
+// When such a copy already exists, a number is appended as well, as in
+// main.go.orig.1, so that earlier backups are kept.
+const BackupSuffix = ".orig"
+
+// ParseConflictPolicy returns the conflict policy called s.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	names := make([]string, 0, len(ConflictPolicies))
+
+	for _, p := range ConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+
+		names = append(names, string(p))
+	}
+
+	return "", fmt.Errorf("unknown conflict policy %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// WriteAction is what writing the generated files does to a path.
+type WriteAction string
+
+const (
+	WriteCreated     WriteAction = "created"
+	WriteOverwritten WriteAction = "overwritten"
+	WriteSkipped     WriteAction = "skipped"
+	// WriteBackedUp is a file overwritten after being copied to a backup.
+	WriteBackedUp  WriteAction = "backed up"
+	WriteUnchanged WriteAction = "unchanged"
+)
+
+// WriteResult is the action taken for a single path.
+type WriteResult struct {
+	Path   string
+	Action WriteAction
+}
+
+// ConflictError lists the files that differ from the generated ones when the
+// policy is ConflictFail.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d existing file(s) differ from the generated ones: %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// ResolveConflicts compares files with the contents of dir and applies
+// policy to the files that differ. It returns the files left to write,
+// including the backups, and what happens to every path, sorted by path.
+// Create-only files that already exist are always skipped.
+//
+// With ConflictPrompt, prompt is called for every conflict and must answer
+// with ConflictSkip, ConflictOverwrite, ConflictBackup or ConflictFail.
+func ResolveConflicts(dir string, files map[string]File, policy ConflictPolicy, prompt func(Change) (ConflictPolicy, error)) (map[string]File, []WriteResult, error) {
+	changes, err := Plan(dir, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		out       = make(map[string]File, len(files))
+		results   = make([]WriteResult, 0, len(changes))
+		conflicts []string
+	)
+
+	for _, change := range changes {
+		f := files[change.Path]
+
+		switch change.Kind {
+		case ChangeNew:
+			out[change.Path] = f
+			results = append(results, WriteResult{Path: change.Path, Action: WriteCreated})
+
+			continue
+		case ChangeSkipped:
+			results = append(results, WriteResult{Path: change.Path, Action: WriteSkipped})
+
+			continue
+		case ChangeUnchanged:
+			results = append(results, WriteResult{Path: change.Path, Action: WriteUnchanged})
+
+			continue
+		}
+
+		resolved := policy
+		if policy == ConflictPrompt {
+			if resolved, err = prompt(change); err != nil {
+				return nil, nil, err
+			}
+
+			if resolved == ConflictFail {
+				return nil, nil, &ConflictError{Paths: []string{change.Path}}
+			}
+		}
+
+		switch resolved {
+		case ConflictSkip:
+			results = append(results, WriteResult{Path: change.Path, Action: WriteSkipped})
+		case ConflictOverwrite:
+			out[change.Path] = f
+			results = append(results, WriteResult{Path: change.Path, Action: WriteOverwritten})
+		case ConflictBackup:
+			info, err := os.Stat(filepath.Join(dir, change.Path))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to back up %s: %w", change.Path, err)
+			}
+
+			backup, err := backupPath(dir, change.Path, func(p string) bool {
+				_, generated := files[p]
+				_, written := out[p]
+
+				return generated || written
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to back up %s: %w", change.Path, err)
+			}
+
+			out[change.Path] = f
+			out[backup] = File{
+				Path:    backup,
+				Content: change.Old,
+				Mode:    info.Mode() & fs.ModePerm,
+			}
+			results = append(results, WriteResult{Path: change.Path, Action: WriteBackedUp})
+		case ConflictFail:
+			conflicts = append(conflicts, change.Path)
+		default:
+			return nil, nil, fmt.Errorf("invalid conflict policy %q for %s", resolved, change.Path)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, nil, &ConflictError{Paths: conflicts}
+	}
+
+	return out, results, nil
+}
+
+// backupPath returns the first backup path of path that is neither in dir
+// nor taken.
+func backupPath(dir, path string, taken func(string) bool) (string, error) {
+	backup := path + BackupSuffix
+
+	for i := 1; ; i++ {
+		_, err := os.Lstat(filepath.Join(dir, backup))
+		if errors.Is(err, fs.ErrNotExist) {
+			if !taken(backup) {
+				return backup, nil
+			}
+		} else if err != nil {
+			return "", err
+		}
+
+		backup = path + BackupSuffix + "." + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,235 @@
+package craft_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// conflictProject writes an existing project to a new directory and returns
+// it along with the files generated for it: new.txt is new, same.txt is
+// unchanged, a.txt and b.sh differ and README.md differs but is only
+// created.
+func conflictProject(t *testing.T) (string, map[string]craft.File) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	writeFile(t, dir, "same.txt", []byte("same\n"))
+	writeFile(t, dir, "a.txt", []byte("old a\n"))
+	writeFile(t, dir, "b.sh", []byte("old b\n"))
+	writeFile(t, dir, "README.md", []byte("edited\n"))
+
+	files := map[string]craft.File{
+		"new.txt":   {Path: "new.txt", Content: []byte("new\n")},
+		"same.txt":  {Path: "same.txt", Content: []byte("same\n")},
+		"a.txt":     {Path: "a.txt", Content: []byte("new a\n")},
+		"b.sh":      {Path: "b.sh", Content: []byte("new b\n"), Mode: 0o755},
+		"README.md": {Path: "README.md", Content: []byte("generated\n"), CreateOnly: true},
+	}
+
+	return dir, files
+}
+
+func TestResolveConflicts(t *testing.T) {
+	tests := []struct {
+		policy  craft.ConflictPolicy
+		written map[string]string
+		actions map[string]craft.WriteAction
+	}{
+		{
+			policy:  craft.ConflictSkip,
+			written: map[string]string{"new.txt": "new\n"},
+			actions: map[string]craft.WriteAction{"a.txt": craft.WriteSkipped, "b.sh": craft.WriteSkipped},
+		},
+		{
+			policy:  craft.ConflictOverwrite,
+			written: map[string]string{"new.txt": "new\n", "a.txt": "new a\n", "b.sh": "new b\n"},
+			actions: map[string]craft.WriteAction{"a.txt": craft.WriteOverwritten, "b.sh": craft.WriteOverwritten},
+		},
+		{
+			policy: craft.ConflictBackup,
+			written: map[string]string{
+				"new.txt":    "new\n",
+				"a.txt":      "new a\n",
+				"a.txt.orig": "old a\n",
+				"b.sh":       "new b\n",
+				"b.sh.orig":  "old b\n",
+			},
+			actions: map[string]craft.WriteAction{"a.txt": craft.WriteBackedUp, "b.sh": craft.WriteBackedUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			dir, files := conflictProject(t)
+
+			out, results, err := craft.ResolveConflicts(dir, files, tt.policy, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertWritten(t, out, tt.written)
+
+			actions := map[string]craft.WriteAction{
+				"new.txt":   craft.WriteCreated,
+				"same.txt":  craft.WriteUnchanged,
+				"README.md": craft.WriteSkipped,
+			}
+			for path, action := range tt.actions {
+				actions[path] = action
+			}
+
+			assertResults(t, results, actions)
+		})
+	}
+}
+
+func TestResolveConflictsFail(t *testing.T) {
+	dir, files := conflictProject(t)
+
+	_, _, err := craft.ResolveConflicts(dir, files, craft.ConflictFail, nil)
+
+	var conflict *craft.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got error %v, want a ConflictError", err)
+	}
+
+	if want := []string{"a.txt", "b.sh"}; !reflect.DeepEqual(conflict.Paths, want) {
+		t.Errorf("got conflicts %v, want %v", conflict.Paths, want)
+	}
+}
+
+func TestResolveConflictsBackupMode(t *testing.T) {
+	dir, files := conflictProject(t)
+
+	if err := os.Chmod(filepath.Join(dir, "b.sh"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, err := craft.ResolveConflicts(dir, files, craft.ConflictBackup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := out["b.sh.orig"].Mode; mode != 0o750 {
+		t.Errorf("got backup mode %v, want %v", mode, fs.FileMode(0o750))
+	}
+}
+
+// Existing backups are kept, the new ones taking the next free name.
+func TestResolveConflictsExistingBackup(t *testing.T) {
+	dir, files := conflictProject(t)
+	writeFile(t, dir, "a.txt.orig", []byte("first backup\n"))
+	writeFile(t, dir, "a.txt.orig.1", []byte("second backup\n"))
+	files["b.sh.orig"] = craft.File{Path: "b.sh.orig", Content: []byte("generated\n")}
+
+	out, _, err := craft.ResolveConflicts(dir, files, craft.ConflictBackup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertWritten(t, out, map[string]string{
+		"new.txt":      "new\n",
+		"a.txt":        "new a\n",
+		"a.txt.orig.2": "old a\n",
+		"b.sh":         "new b\n",
+		"b.sh.orig":    "generated\n",
+		"b.sh.orig.1":  "old b\n",
+	})
+}
+
+func TestResolveConflictsPrompt(t *testing.T) {
+	dir, files := conflictProject(t)
+
+	var asked []string
+
+	answers := map[string]craft.ConflictPolicy{
+		"a.txt": craft.ConflictSkip,
+		"b.sh":  craft.ConflictBackup,
+	}
+
+	out, results, err := craft.ResolveConflicts(dir, files, craft.ConflictPrompt, func(change craft.Change) (craft.ConflictPolicy, error) {
+		asked = append(asked, change.Path)
+
+		if string(change.Old) != "old "+change.Path[:1]+"\n" {
+			t.Errorf("got old content %q for %s", change.Old, change.Path)
+		}
+
+		return answers[change.Path], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a.txt", "b.sh"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("asked for %v, want %v", asked, want)
+	}
+
+	assertWritten(t, out, map[string]string{"new.txt": "new\n", "b.sh": "new b\n", "b.sh.orig": "old b\n"})
+	assertResults(t, results, map[string]craft.WriteAction{
+		"new.txt":   craft.WriteCreated,
+		"same.txt":  craft.WriteUnchanged,
+		"README.md": craft.WriteSkipped,
+		"a.txt":     craft.WriteSkipped,
+		"b.sh":      craft.WriteBackedUp,
+	})
+}
+
+func TestResolveConflictsPromptAbort(t *testing.T) {
+	dir, files := conflictProject(t)
+
+	_, _, err := craft.ResolveConflicts(dir, files, craft.ConflictPrompt, func(craft.Change) (craft.ConflictPolicy, error) {
+		return craft.ConflictFail, nil
+	})
+
+	var conflict *craft.ConflictError
+	if !errors.As(err, &conflict) || !reflect.DeepEqual(conflict.Paths, []string{"a.txt"}) {
+		t.Errorf("got error %v, want a conflict on a.txt", err)
+	}
+
+	errPrompt := errors.New("no terminal")
+
+	_, _, err = craft.ResolveConflicts(dir, files, craft.ConflictPrompt, func(craft.Change) (craft.ConflictPolicy, error) {
+		return "", errPrompt
+	})
+	if !errors.Is(err, errPrompt) {
+		t.Errorf("got error %v, want %v", err, errPrompt)
+	}
+}
+
+func assertWritten(t *testing.T, out map[string]craft.File, want map[string]string) {
+	t.Helper()
+
+	got := make(map[string]string, len(out))
+	for path, f := range out {
+		got[path] = string(f.Content)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+}
+
+func assertResults(t *testing.T, results []craft.WriteResult, want map[string]craft.WriteAction) {
+	t.Helper()
+
+	if !sort.SliceIsSorted(results, func(i, j int) bool { return results[i].Path < results[j].Path }) {
+		t.Errorf("results are not sorted by path: %v", results)
+	}
+
+	got := make(map[string]craft.WriteAction, len(results))
+	for _, r := range results {
+		got[r.Path] = r.Action
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got actions %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+// Package crafttest compares generated projects against golden files.
+//
+// A golden file is a txtar archive holding one "-- path --" section per
+// generated file, so that a change to the templates shows up as a readable
+// diff of the golden files. Run the tests with -update to rewrite them.
+package crafttest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files instead of comparing against them")
+
+// Golden compares files with the golden file at path, reporting every file
+// that is missing, unexpected or different. With -update, it rewrites the
+// golden file instead.
+func Golden(t testing.TB, path string, files map[string][]byte) {
+	t.Helper()
+
+	got, err := Marshal(files)
+	if err != nil {
+		t.Fatalf("failed to encode files: %v", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run the tests with -update to create it: %v", err)
+	}
+
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	wantFiles := Unmarshal(want)
+	gotFiles := Unmarshal(got)
+
+	for _, p := range sortedKeys(wantFiles, gotFiles) {
+		w, inWant := wantFiles[p]
+		g, inGot := gotFiles[p]
+
+		switch {
+		case !inGot:
+			t.Errorf("%s: missing", p)
+		case !inWant:
+			t.Errorf("%s: unexpected", p)
+		case !bytes.Equal(w, g):
+			t.Errorf("%s: differs from %s:\n%s", p, path, craft.UnifiedDiff("golden/"+p, "generated/"+p, w, g))
+		}
+	}
+
+	t.Log("run the tests with -update to accept the changes")
+}
+
+// noNewline follows the content of a file that does not end with a newline,
+// as in a unified diff.
+const noNewline = "\\ No newline at end of file\n"
+
+// Marshal encodes files as a txtar archive, sorted by path. A file that does
+// not end with a newline is followed by a "\ No newline at end of file" line,
+// so that the archive keeps its exact content.
+func Marshal(files map[string][]byte) ([]byte, error) {
+	entries := make(map[string]craft.File, len(files))
+	for p, content := range files {
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(append(content[:len(content):len(content)], '\n'), noNewline...)
+		}
+
+		entries[p] = craft.File{Path: p, Content: content}
+	}
+
+	var buf bytes.Buffer
+
+	if err := craft.WriteFiles(context.Background(), craft.NewTxtarSink(&buf, ""), entries); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a txtar archive written by Marshal.
+func Unmarshal(data []byte) map[string][]byte {
+	files := make(map[string][]byte)
+
+	var (
+		name    string
+		content []byte
+		inFile  bool
+	)
+
+	add := func() {
+		if trimmed, ok := bytes.CutSuffix(content, []byte("\n"+noNewline)); ok {
+			content = trimmed
+		}
+
+		files[name] = content
+	}
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if p, ok := fileMarker(line); ok {
+			if inFile {
+				add()
+			}
+
+			name, content, inFile = p, []byte{}, true
+
+			continue
+		}
+
+		if inFile {
+			content = append(content, line...)
+		}
+	}
+
+	if inFile {
+		add()
+	}
+
+	return files
+}
+
+// fileMarker returns the path of a "-- path --" line.
+func fileMarker(line []byte) (string, bool) {
+	s := strings.TrimSuffix(string(line), "\n")
+	if !strings.HasPrefix(s, "-- ") || !strings.HasSuffix(s, " --") || len(s) < 7 {
+		return "", false
+	}
+
+	return strings.TrimSpace(s[3 : len(s)-3]), true
+}
+
+func sortedKeys(maps ...map[string][]byte) []string {
+	seen := make(map[string]bool)
+
+	var keys []string
+
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
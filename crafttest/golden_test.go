@@ -0,0 +1,126 @@
+package crafttest_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft/crafttest"
+)
+
+// recorder is a testing.TB keeping the errors reported to it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Log(args ...any) {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.TB.Fatalf(format, args...)
+}
+
+func TestMarshal(t *testing.T) {
+	files := map[string][]byte{
+		"b.txt":        []byte("b\n"),
+		"a/no-newline": []byte("one\ntwo"),
+		"empty":        {},
+		"blank-lines":  []byte("x\n\n\n"),
+	}
+
+	got, err := crafttest.Marshal(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "-- a/no-newline --\none\ntwo\n\\ No newline at end of file\n" +
+		"-- b.txt --\nb\n" +
+		"-- blank-lines --\nx\n\n\n" +
+		"-- empty --\n"
+
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if back := crafttest.Unmarshal(got); !reflect.DeepEqual(back, files) {
+		t.Errorf("got %q back, want %q", back, files)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	got := crafttest.Unmarshal([]byte("comment\n-- a --\nA\n-- not a marker\n--  b  --\nB"))
+
+	want := map[string][]byte{
+		"a": []byte("A\n-- not a marker\n"),
+		"b": []byte("B"),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.txtar")
+
+	golden := "-- changed --\nbefore\n-- missing --\nm\n-- newline --\nn\n-- same --\ns\n"
+	if err := os.WriteFile(path, []byte(golden), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+
+	crafttest.Golden(r, path, map[string][]byte{
+		"changed":    []byte("after\n"),
+		"newline":    []byte("n"),
+		"same":       []byte("s\n"),
+		"unexpected": []byte("u\n"),
+	})
+
+	var got []string
+	for _, e := range r.errors {
+		got = append(got, strings.SplitN(e, "\n", 2)[0])
+	}
+
+	want := []string{
+		"changed: differs from " + path + ":",
+		"missing: missing",
+		"newline: differs from " + path + ":",
+		"unexpected: unexpected",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got errors %q, want %q", got, want)
+	}
+}
+
+func TestGoldenEqual(t *testing.T) {
+	files := map[string][]byte{"a": []byte("a"), "b": []byte("b\n")}
+
+	content, err := crafttest.Marshal(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "golden.txtar")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+
+	crafttest.Golden(r, path, files)
+
+	if len(r.errors) > 0 {
+		t.Errorf("got errors %q", r.errors)
+	}
+}
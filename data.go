@@ -0,0 +1,143 @@
+package craft
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ConfigFormats lists the accepted configuration file formats.
+var ConfigFormats = []string{"yml", "yaml", "json", "toml"}
+
+var (
+	projectNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	goVersionRe   = regexp.MustCompile(`^1\.[0-9]+(\.[0-9]+)?$`)
+	binaryRe      = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	envPrefixRe   = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// Validate checks the data before any generator runs and returns every
+// violation at once.
+func (d Data) Validate() error {
+	var errs Errors
+
+	if err := ValidateProjectName(d.ProjectName); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := ValidateModulePath(d.ModulePrefix); err != nil {
+		errs = append(errs, err)
+	}
+
+	if d.Module != "" && d.Module != d.ModulePrefix {
+		if err := ValidateModulePath(d.Module); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !goVersionRe.MatchString(d.GoVersion) {
+		errs = append(errs, fmt.Errorf("invalid Go version %q: expected a release such as 1.21 or 1.21.5", d.GoVersion))
+	}
+
+	errs = append(errs, validateBinaries(d.Binaries)...)
+	errs = append(errs, validateCommands(d)...)
+
+	if d.EnvPrefix != "" && !envPrefixRe.MatchString(d.EnvPrefix) {
+		errs = append(errs, fmt.Errorf("invalid env prefix %q: use upper case letters, digits and underscores, starting with a letter", d.EnvPrefix))
+	}
+
+	if !contains(ConfigFormats, d.ConfigFormat) {
+		errs = append(errs, fmt.Errorf("invalid config format %q, expected one of %s", d.ConfigFormat, strings.Join(ConfigFormats, ", ")))
+	}
+
+	if err := validateFramework(d); err != nil {
+		errs = append(errs, fmt.Errorf("%w, expected one of %s", err, strings.Join(CLIFrameworks, ", ")))
+	}
+
+	if err := validateLicense(d); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, name := range d.Includes {
+		if name == NoFeatures {
+			if len(d.Includes) > 1 {
+				errs = append(errs, fmt.Errorf("feature %s cannot be included along with other features", NoFeatures))
+			}
+
+			continue
+		}
+
+		if err := checkFeatures([]string{name}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// ValidateProjectName checks that name can name the project directory.
+func ValidateProjectName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("a project name is required")
+	case !projectNameRe.MatchString(name):
+		return fmt.Errorf("invalid project name %q: use letters, digits, dots, dashes and underscores, starting with a letter or digit", name)
+	}
+
+	return nil
+}
+
+// ValidateModulePath checks that path is a valid Go module path.
+func ValidateModulePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("a module path is required")
+	}
+
+	if err := module.CheckImportPath(path); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateBinaries checks that every binary can name a directory of cmd and
+// a Go package.
+func ValidateBinaries(binaries []string) error {
+	return Errors(validateBinaries(binaries)).ErrorOrNil()
+}
+
+func validateBinaries(binaries []string) []error {
+	if len(binaries) == 0 {
+		return []error{fmt.Errorf("at least one binary is required")}
+	}
+
+	var errs []error
+
+	packages := make(map[string]string)
+
+	for _, binary := range binaries {
+		if !binaryRe.MatchString(binary) {
+			errs = append(errs, fmt.Errorf("invalid binary name %q: use letters, digits, dashes and underscores, starting with a letter", binary))
+			continue
+		}
+
+		pkg := CommandOptions{Binary: binary}.PackageName()
+
+		if token.IsKeyword(pkg) {
+			errs = append(errs, fmt.Errorf("invalid binary name %q: its package name %s is a Go keyword", binary, pkg))
+			continue
+		}
+
+		if prev, ok := packages[pkg]; ok {
+			errs = append(errs, fmt.Errorf("binaries %q and %q share the package name %s", prev, binary, pkg))
+			continue
+		}
+
+		packages[pkg] = binary
+	}
+
+	return errs
+}
@@ -0,0 +1,336 @@
+package craft_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// errorMessages returns the messages of the errors collected in err.
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var errs craft.Errors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+
+	return msgs
+}
+
+// assertErrors checks that err holds one error per item of want, each
+// containing that item.
+func assertErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+
+	got := errorMessages(err)
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d errors %q, want %d %q", len(got), got, len(want), want)
+	}
+
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("got error %q, want it to contain %q", got[i], want[i])
+		}
+	}
+}
+
+func TestValidateProjectName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "example"},
+		{name: "my-app_2.0"},
+		{name: "9lives"},
+		{name: "", want: "a project name is required"},
+		{name: "-app", want: "invalid project name"},
+		{name: "my app", want: "invalid project name"},
+		{name: "../app", want: "invalid project name"},
+		{name: "app/cmd", want: "invalid project name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := craft.ValidateProjectName(tt.name)
+
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateModulePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "github.com/example/example"},
+		{path: "example.com/app/v2"},
+		{path: "example"},
+		{path: "", want: "a module path is required"},
+		{path: "github.com/example/my app", want: "invalid module path"},
+		{path: "/github.com/example", want: "invalid module path"},
+		{path: "github.com/example/", want: "invalid module path"},
+		{path: "github.com//example", want: "invalid module path"},
+		{path: "github.com/../example", want: "invalid module path"},
+		{path: "github.com/example/app@v1", want: "invalid module path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := craft.ValidateModulePath(tt.path)
+
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBinaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		binaries []string
+		want     []string
+	}{
+		{name: "single", binaries: []string{"example"}},
+		{name: "several", binaries: []string{"examplectl", "exampled", "example-agent"}},
+		{name: "none", want: []string{"at least one binary is required"}},
+		{name: "invalid name", binaries: []string{"2fa", "my app"}, want: []string{`invalid binary name "2fa"`, `invalid binary name "my app"`}},
+		{name: "keyword", binaries: []string{"go"}, want: []string{"is a Go keyword"}},
+		{name: "duplicate", binaries: []string{"example", "example"}, want: []string{`binaries "example" and "example" share the package name example`}},
+		{name: "same package", binaries: []string{"my-app", "my_app", "myapp"}, want: []string{`binaries "my-app" and "my_app" share the package name myapp`, `binaries "my-app" and "myapp" share the package name myapp`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrors(t, craft.ValidateBinaries(tt.binaries), tt.want)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *craft.Data)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(d *craft.Data) {},
+		},
+		{
+			name: "every violation at once",
+			modify: func(d *craft.Data) {
+				d.ProjectName = "my app"
+				d.ModulePrefix = "github.com/example/my app"
+				d.Module = d.ModulePrefix
+				d.GoVersion = "2"
+				d.Binaries = []string{"example", "example"}
+				d.EnvPrefix = "lower"
+				d.ConfigFormat = "ini"
+				d.Framework = "gin"
+				d.License = "wtfpl"
+				d.Includes = []string{"docker", "nope"}
+			},
+			want: []string{
+				"invalid project name",
+				"invalid module path",
+				`invalid Go version "2"`,
+				`binaries "example" and "example" share the package name example`,
+				`invalid env prefix "lower"`,
+				`invalid config format "ini"`,
+				"invalid cli framework: gin",
+				`unknown license "wtfpl"`,
+				"nope",
+			},
+		},
+		{
+			name: "module differing from the prefix",
+			modify: func(d *craft.Data) {
+				d.Module = "github.com/example/my app"
+			},
+			want: []string{"invalid module path"},
+		},
+		{
+			name: "commands",
+			modify: func(d *craft.Data) {
+				d.Binaries = []string{"examplectl", "exampled"}
+				d.Commands = []craft.Command{
+					{Binary: "examplectl", Name: "migrate"},
+					{Binary: "examplectl", Parent: "migrate", Name: "up"},
+					{Binary: "exampled", Parent: "server", Name: "reload"},
+				}
+			},
+		},
+		{
+			name: "no features",
+			modify: func(d *craft.Data) {
+				d.Includes = []string{craft.NoFeatures}
+			},
+		},
+		{
+			name: "no features along with others",
+			modify: func(d *craft.Data) {
+				d.Includes = []string{craft.NoFeatures, "docker"}
+			},
+			want: []string{"feature none cannot be included along with other features"},
+		},
+		{
+			name: "unknown binary",
+			modify: func(d *craft.Data) {
+				d.Commands = []craft.Command{{Binary: "examplectl", Name: "migrate"}}
+			},
+			want: []string{`command migrate: unknown binary "examplectl"`},
+		},
+		{
+			name: "invalid command name",
+			modify: func(d *craft.Data) {
+				d.Commands = []craft.Command{{Binary: "example", Name: "Migrate"}}
+			},
+			want: []string{`invalid command name "Migrate"`},
+		},
+		{
+			name: "unknown parent",
+			modify: func(d *craft.Data) {
+				d.Commands = []craft.Command{{Binary: "example", Parent: "db", Name: "migrate"}}
+			},
+			want: []string{`command db migrate: parent command "db" of example does not exist`},
+		},
+		{
+			name: "server parent without the server feature",
+			modify: func(d *craft.Data) {
+				d.Includes = []string{"cli"}
+				d.Commands = []craft.Command{{Binary: "example", Parent: "server", Name: "reload"}}
+			},
+			want: []string{`parent command "server" of example does not exist`},
+		},
+		{
+			name: "clashing commands",
+			modify: func(d *craft.Data) {
+				d.Commands = []craft.Command{
+					{Binary: "example", Name: "version"},
+					{Binary: "example", Name: "db-migrate"},
+					{Binary: "example", Name: "db"},
+					{Binary: "example", Parent: "db", Name: "migrate"},
+				}
+			},
+			want: []string{
+				"command version of example clashes with version",
+				"command db migrate of example clashes with db-migrate",
+			},
+		},
+		{
+			name: "flags",
+			modify: func(d *craft.Data) {
+				d.Commands = []craft.Command{{Binary: "example", Name: "migrate", Flags: []craft.CommandFlag{
+					{Name: "dry-run", Type: "bool"},
+					{Name: "timeout", Type: "duration"},
+					{Name: "Verbose", Type: "bool"},
+					{Name: "count", Type: "uint"},
+					{Name: "timeout", Type: "duration"},
+				}}}
+			},
+			want: []string{
+				`command migrate: invalid flag name "Verbose"`,
+				`command migrate: invalid type "uint" of flag count`,
+				"command migrate: duplicate flag timeout",
+			},
+		},
+		{
+			name: "reserved flag names",
+			modify: func(d *craft.Data) {
+				var flags []craft.CommandFlag
+				for _, name := range []string{"ctx", "app-ctx", "cmd", "args", "c"} {
+					flags = append(flags, craft.CommandFlag{Name: name, Type: "string"})
+				}
+
+				d.Commands = []craft.Command{{Binary: "example", Name: "migrate", Flags: flags}}
+			},
+			want: []string{
+				`flag name "ctx" is reserved`,
+				`flag name "app-ctx" is reserved`,
+				`flag name "cmd" is reserved`,
+				`flag name "args" is reserved`,
+				`flag name "c" is reserved`,
+			},
+		},
+		{
+			name: "keyword flag names",
+			modify: func(d *craft.Data) {
+				var flags []craft.CommandFlag
+				for _, name := range []string{"go", "type", "range", "func"} {
+					flags = append(flags, craft.CommandFlag{Name: name, Type: "string"})
+				}
+
+				d.Commands = []craft.Command{{Binary: "example", Name: "migrate", Flags: flags}}
+			},
+			want: []string{
+				`flag name "go" is reserved`,
+				`flag name "type" is reserved`,
+				`flag name "range" is reserved`,
+				`flag name "func" is reserved`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testData()
+			data.Framework = "cobra"
+			tt.modify(&data)
+
+			assertErrors(t, data.Validate(), tt.want)
+		})
+	}
+}
+
+func TestHasFeature(t *testing.T) {
+	tests := []struct {
+		includes []string
+		want     map[string]bool
+	}{
+		{includes: nil, want: map[string]bool{"docker": true, "server": true}},
+		{includes: []string{craft.NoFeatures}, want: map[string]bool{"docker": false, "cli": false}},
+		{includes: []string{"server"}, want: map[string]bool{"server": true, "cli": true, "docker": false}},
+	}
+
+	for _, tt := range tests {
+		data := testData()
+		data.Includes = tt.includes
+
+		for name, want := range tt.want {
+			if got := data.HasFeature(name); got != want {
+				t.Errorf("includes %v: got HasFeature(%s) %v, want %v", tt.includes, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package craft
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each hunk.
+const diffContext = 3
+
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+type diffLine struct {
+	Op   diffOp
+	Text string
+}
+
+// UnifiedDiff renders a unified diff turning a into b. It returns an empty
+// string when both contents are identical.
+func UnifiedDiff(oldName, newName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	out := new(strings.Builder)
+	fmt.Fprintf(out, "--- %s\n", oldName)
+	fmt.Fprintf(out, "+++ %s\n", newName)
+
+	for _, h := range hunks(lines) {
+		writeHunk(out, lines[h[0]:h[1]], h[2], h[3])
+	}
+
+	return out.String()
+}
+
+// splitLines splits content into lines, keeping the trailing newline of
+// each line so that a missing final newline is preserved.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes a line based edit script using the longest common
+// subsequence of a and b.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]diffLine, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		out = append(out, diffLine{opEqual, l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] holds the length of the LCS of ma[i:] and mb[j:].
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(ma) && j < len(mb) {
+		switch {
+		case ma[i] == mb[j]:
+			out = append(out, diffLine{opEqual, ma[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{opDelete, ma[i]})
+			i++
+		default:
+			out = append(out, diffLine{opInsert, mb[j]})
+			j++
+		}
+	}
+	for ; i < len(ma); i++ {
+		out = append(out, diffLine{opDelete, ma[i]})
+	}
+	for ; j < len(mb); j++ {
+		out = append(out, diffLine{opInsert, mb[j]})
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, diffLine{opEqual, l})
+	}
+
+	return out
+}
+
+// hunks groups the edit script into hunks. Each hunk is described by its
+// [start, end) range in lines and the 1-based starting line in a and b.
+func hunks(lines []diffLine) [][4]int {
+	var out [][4]int
+
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].Op == opEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := max(i-diffContext, 0)
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != opEqual {
+				switch lines[end].Op {
+				case opDelete:
+					oldLine++
+				case opInsert:
+					newLine++
+				}
+				end++
+				continue
+			}
+
+			// Merge with the next change when the gap fits in the context.
+			next := end
+			for next < len(lines) && lines[next].Op == opEqual {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				break
+			}
+			oldLine += next - end
+			newLine += next - end
+			end = next
+		}
+
+		stop := min(end+diffContext, len(lines))
+		oldLine += stop - end
+		newLine += stop - end
+
+		out = append(out, [4]int{start, stop, oldStart, newStart})
+		i = stop
+	}
+
+	return out
+}
+
+func writeHunk(out *strings.Builder, lines []diffLine, oldStart, newStart int) {
+	oldCount, newCount := 0, 0
+	for _, l := range lines {
+		switch l.Op {
+		case opEqual:
+			oldCount++
+			newCount++
+		case opDelete:
+			oldCount++
+		case opInsert:
+			newCount++
+		}
+	}
+
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, l := range lines {
+		prefix := " "
+		switch l.Op {
+		case opDelete:
+			prefix = "-"
+		case opInsert:
+			prefix = "+"
+		}
+
+		out.WriteString(prefix)
+		out.WriteString(l.Text)
+		if !strings.HasSuffix(l.Text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,108 @@
+package craft_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+	"github.com/edsonmichaque/craft/crafttest"
+)
+
+// numbered returns "line N" for every N from from to to, or the replacement
+// given for N.
+func numbered(from, to int, replace map[int]string) string {
+	var b strings.Builder
+
+	for i := from; i <= to; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line)
+			continue
+		}
+
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{name: "unchanged", a: numbered(1, 5, nil), b: numbered(1, 5, nil)},
+		{name: "created", b: numbered(1, 3, nil)},
+		{name: "emptied", a: numbered(1, 3, nil)},
+		{name: "modified", a: numbered(1, 10, nil), b: numbered(1, 10, map[int]string{5: "line five\n"})},
+		{name: "inserted", a: numbered(1, 10, nil), b: numbered(1, 10, map[int]string{5: "line 5\nline 5.5\n"})},
+		{name: "deleted", a: numbered(1, 10, nil), b: numbered(1, 10, map[int]string{1: "", 10: ""})},
+		{name: "merged hunks", a: numbered(1, 20, nil), b: numbered(1, 20, map[int]string{5: "line five\n", 10: "line ten\n"})},
+		{name: "separate hunks", a: numbered(1, 20, nil), b: numbered(1, 20, map[int]string{2: "line two\n", 18: "line eighteen\n"})},
+		{name: "newline removed", a: "one\ntwo\n", b: "one\ntwo"},
+		{name: "newline added", a: "one\ntwo", b: "one\ntwo\n"},
+	}
+
+	files := make(map[string][]byte, len(tests))
+
+	for _, tt := range tests {
+		files[tt.name] = []byte(craft.UnifiedDiff("a/file", "b/file", []byte(tt.a), []byte(tt.b)))
+	}
+
+	if len(files["unchanged"]) != 0 {
+		t.Errorf("got a diff of identical contents:\n%s", files["unchanged"])
+	}
+
+	crafttest.Golden(t, filepath.Join("testdata", "diff.txtar"), files)
+}
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "go.mod", []byte("module example.com/old\n"))
+	writeFile(t, dir, "main.go", []byte("package main\n"))
+	writeFile(t, dir, "README.md", []byte("# Edited\n"))
+	writeFile(t, dir, "extra.txt", []byte("not generated\n"))
+
+	changes, err := craft.Plan(dir, map[string]craft.File{
+		"go.mod":            {Path: "go.mod", Content: []byte("module example.com/new\n\ngo 1.21\n")},
+		"main.go":           {Path: "main.go", Content: []byte("package main\n")},
+		"README.md":         {Path: "README.md", Content: []byte("# Example\n"), CreateOnly: true},
+		"internal/app.go":   {Path: "internal/app.go", Content: []byte("package internal\n")},
+		"config/config.yml": {Path: "config/config.yml", Content: []byte("debug: false\n"), CreateOnly: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte, len(changes))
+	paths := make([]string, 0, len(changes))
+
+	for _, c := range changes {
+		files[c.Path] = []byte(fmt.Sprintf("%s\n%s", c.Kind, c.Diff()))
+		paths = append(paths, c.Path)
+	}
+
+	if want := []string{"README.md", "config/config.yml", "go.mod", "internal/app.go", "main.go"}; strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("got changes for %v, want %v", paths, want)
+	}
+
+	crafttest.Golden(t, filepath.Join("testdata", "plan.txtar"), files)
+}
+
+func TestPlanError(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(dir, "main.go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := craft.Plan(dir, map[string]craft.File{
+		"main.go": {Path: "main.go", Content: []byte("package main\n")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to read main.go") {
+		t.Errorf("got error %v, want a read error", err)
+	}
+}
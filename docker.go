@@ -5,31 +5,37 @@ import "fmt"
 func GenerateDockerFiles(data Data) (map[string]RenderOptions, error) {
 	out := make(map[string]RenderOptions)
 
+	// The Dockerfiles and the main compose file belong to the docker
+	// feature, so that including a service alone only adds its compose
+	// file.
+	docker := map[string]RenderOptions{
+		"docker/README.md":          renderOptions(data, "docker/readme.md.tmpl"),
+		"docker/docker-compose.yml": renderOptions(data, "docker/docker-compose.yml.tmpl"),
+	}
+
 	// Generate Dockerfiles for each binary
 	for _, binary := range data.Binaries {
 		filename := "docker/Dockerfile"
 		if len(data.Binaries) > 1 {
 			filename = fmt.Sprintf("docker/%s.Dockerfile", binary)
 		}
-		out[filename] = renderOptions(DockerfileOptions{Binary: binary, Data: data}, "docker/dockerfile.tmpl")
+		docker[filename] = renderOptions(DockerfileOptions{Binary: binary, Data: data}, "docker/dockerfile.tmpl")
 	}
 
-	// Additional Docker-related files
-	additionalFiles := map[string]string{
-		"docker/README.md":                     "docker/readme.md.tmpl",
-		"docker/docker-compose.yml":            "docker/docker-compose.yml.tmpl",
-		"docker/mysql/docker-compose.yml":      "docker/mysql/docker-compose.yml.tmpl",
-		"docker/postgres/docker-compose.yml":   "docker/postgres/docker-compose.yml.tmpl",
-		"docker/mariadb/docker-compose.yml":    "docker/mariadb/docker-compose.yml.tmpl",
-		"docker/redis/docker-compose.yml":      "docker/redis/docker-compose.yml.tmpl",
-		"docker/grafana/docker-compose.yml":    "docker/grafana/docker-compose.yml.tmpl",
-		"docker/prometheus/docker-compose.yml": "docker/prometheus/docker-compose.yml.tmpl",
-		"docker/rabbitmq/docker-compose.yml":   "docker/rabbitmq/docker-compose.yml.tmpl",
-		"docker/jaeger/docker-compose.yml":     "docker/jaeger/docker-compose.yml.tmpl",
+	for filename, opts := range docker {
+		opts.Features = []string{"docker"}
+		out[filename] = opts
 	}
 
-	for k, tmpl := range additionalFiles {
-		out[k] = renderOptions(data, tmpl)
+	// Compose files of the supporting services, each gated on its own
+	// feature
+	services := []string{"mysql", "postgres", "mariadb", "redis", "grafana", "prometheus", "rabbitmq", "jaeger", "kafka", "minio", "localstack"}
+
+	for _, service := range services {
+		opts := renderOptions(data, fmt.Sprintf("docker/%s/docker-compose.yml.tmpl", service))
+		opts.Features = []string{service}
+
+		out[fmt.Sprintf("docker/%s/docker-compose.yml", service)] = opts
 	}
 
 	return out, nil
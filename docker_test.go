@@ -0,0 +1,71 @@
+package craft_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestGenerateDockerFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		want     []string
+	}{
+		{
+			name:     "service only",
+			includes: []string{"postgres"},
+			want:     []string{"docker/postgres/docker-compose.yml"},
+		},
+		{
+			name:     "docker",
+			includes: []string{"docker"},
+			want:     []string{"docker/Dockerfile", "docker/README.md", "docker/docker-compose.yml"},
+		},
+		{
+			name:     "docker and services",
+			includes: []string{"docker", "postgres", "redis"},
+			want: []string{
+				"docker/Dockerfile",
+				"docker/README.md",
+				"docker/docker-compose.yml",
+				"docker/postgres/docker-compose.yml",
+				"docker/redis/docker-compose.yml",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testData()
+			data.Includes = tt.includes
+
+			manager := craft.Manager{
+				Registry: craft.DefaultRegistry(),
+				Options: craft.Options{
+					Templates: os.DirFS(filepath.Join("cmd", "craft", "templates")),
+				},
+			}
+
+			files, err := manager.Render(context.Background(), data, "docker")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			paths := make([]string, 0, len(files))
+			for path := range files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("got files %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
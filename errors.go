@@ -0,0 +1,144 @@
+package craft
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Errors collects several errors into a single one, so that every problem
+// can be reported at once.
+type Errors []error
+
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("%d error(s) occurred:", len(e)))
+
+	for _, err := range e {
+		lines = append(lines, "  "+err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap gives errors.Is and errors.As access to every collected error.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// ErrorOrNil returns nil when no error was collected.
+func (e Errors) ErrorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// flattenErrors returns the errors collected in err when it is an Errors,
+// and err alone otherwise.
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if errs, ok := err.(Errors); ok {
+		var flat []error
+		for _, e := range errs {
+			flat = append(flat, flattenErrors(e)...)
+		}
+
+		return flat
+	}
+
+	return []error{err}
+}
+
+// FileError locates a problem in a generated file. Line and Column are
+// 1-based and zero when unknown.
+type FileError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FileError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	default:
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// TemplateError locates a problem in a template, found while rendering the
+// file at Dst. Line and Column are 1-based and zero when unknown.
+type TemplateError struct {
+	Template string
+	Line     int
+	Column   int
+	Dst      string
+	// Message is the message of Err without the location text/template
+	// puts in front of it, empty when Err carries no location.
+	Message string
+	Err     error
+}
+
+// templateErrorRe matches the location text/template puts in front of its
+// parse and execution errors.
+var templateErrorRe = regexp.MustCompile(`(?s)^template: (.+?):(\d+)(?::(\d+))?: (.*)$`)
+
+// newTemplateError turns an error of text/template into a TemplateError,
+// extracting the location of the problem from the message.
+func newTemplateError(tmpl, dst string, err error) *TemplateError {
+	te := &TemplateError{Template: tmpl, Dst: dst, Err: err}
+
+	m := templateErrorRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return te
+	}
+
+	te.Template = m[1]
+	te.Line, _ = strconv.Atoi(m[2])
+	te.Column, _ = strconv.Atoi(m[3])
+	te.Message = m[4]
+
+	return te
+}
+
+func (e *TemplateError) Error() string {
+	loc := e.Template
+	if loc == "" {
+		loc = e.Dst
+	}
+
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		loc = fmt.Sprintf("%s:%d:%d", loc, e.Line, e.Column)
+	case e.Line > 0:
+		loc = fmt.Sprintf("%s:%d", loc, e.Line)
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = e.Err.Error()
+	}
+
+	if e.Template == "" || e.Dst == "" {
+		return fmt.Sprintf("%s: %s", loc, msg)
+	}
+
+	return fmt.Sprintf("%s: %s (rendering %s)", loc, msg, e.Dst)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
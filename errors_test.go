@@ -0,0 +1,154 @@
+package craft_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/edsonmichaque/craft"
+)
+
+var errFailing = errors.New("failing on purpose")
+
+// failingData fails rendering through its Fail method.
+type failingData struct {
+	craft.Data
+}
+
+func (failingData) Fail() (string, error) {
+	return "", errFailing
+}
+
+func TestErrors(t *testing.T) {
+	var errs craft.Errors
+
+	if err := errs.ErrorOrNil(); err != nil {
+		t.Errorf("got error %v from no errors, want nil", err)
+	}
+
+	errs = append(errs, errFailing, fs.ErrNotExist)
+
+	err := errs.ErrorOrNil()
+	if err == nil {
+		t.Fatal("got no error, want 2")
+	}
+
+	want := "2 error(s) occurred:\n  failing on purpose\n  file does not exist"
+	if err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+
+	if !errors.Is(err, errFailing) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("collected errors are not reachable from %v", err)
+	}
+}
+
+// renderTemplates renders a file with each of templates, returning the
+// errors of the manager.
+func renderTemplates(t *testing.T, templates map[string]string) error {
+	t.Helper()
+
+	mapFS := make(fstest.MapFS, len(templates))
+	for name, content := range templates {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	registry, err := craft.NewRegistry(craft.NewGenerator("files", "Files", func(data craft.Data) (map[string]craft.RenderOptions, error) {
+		out := make(map[string]craft.RenderOptions, len(templates))
+		for name := range templates {
+			out[strings.TrimSuffix(name, ".tmpl")+".txt"] = craft.RenderOptions{
+				Templates: []string{name},
+				Data:      failingData{Data: data},
+			}
+		}
+
+		return out, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := craft.Manager{Registry: registry, Options: craft.Options{Templates: mapFS}}
+
+	_, err = manager.Render(context.Background(), testData(), "files")
+
+	return err
+}
+
+func TestTemplateErrors(t *testing.T) {
+	err := renderTemplates(t, map[string]string{
+		"ok.tmpl":       "{{.ProjectName}}\n",
+		"parse.tmpl":    "line 1\n{{if}}\n",
+		"unclosed.tmpl": "{{range .Binaries}}\n",
+		"exec.tmpl":     "line 1\nline 2\n  {{.Missing}}\n",
+		"fail.tmpl":     "{{.Fail}}\n",
+	})
+
+	// Every broken template is reported, sorted by the file rendered.
+	assertErrors(t, err, []string{
+		"exec.tmpl:3:4: executing \"exec.tmpl\" at <.Missing>: can't evaluate field Missing",
+		"fail.tmpl:1:2: executing \"fail.tmpl\" at <.Fail>: error calling Fail: failing on purpose (rendering fail.txt)",
+		"parse.tmpl:2: missing value for if",
+		"unclosed.tmpl:2: unexpected EOF",
+	})
+
+	var errs craft.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want Errors", err)
+	}
+
+	locations := make(map[string][2]int)
+	for _, e := range errs {
+		var te *craft.TemplateError
+		if !errors.As(e, &te) {
+			t.Fatalf("got error %v, want a TemplateError", e)
+		}
+
+		locations[te.Template] = [2]int{te.Line, te.Column}
+	}
+
+	for tmpl, want := range map[string][2]int{
+		"exec.tmpl":     {3, 4},
+		"fail.tmpl":     {1, 2},
+		"parse.tmpl":    {2, 0},
+		"unclosed.tmpl": {2, 0},
+	} {
+		if got := locations[tmpl]; got != want {
+			t.Errorf("%s: got line and column %v, want %v", tmpl, got, want)
+		}
+	}
+
+	// The original errors stay reachable.
+	if !errors.Is(err, errFailing) {
+		t.Errorf("%v does not wrap the error of Fail", err)
+	}
+
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		t.Errorf("%v does not wrap a template.ExecError", err)
+	}
+}
+
+func TestTemplateErrorRequired(t *testing.T) {
+	err := renderTemplates(t, map[string]string{
+		"required.tmpl": `{{Required "an author is required" ""}}`,
+	})
+
+	var te *craft.TemplateError
+	if !errors.As(err, &te) {
+		t.Fatalf("got error %v, want a TemplateError", err)
+	}
+
+	if te.Line != 1 || !strings.HasSuffix(te.Message, "an author is required") {
+		t.Errorf("got line %d and message %q", te.Line, te.Message)
+	}
+
+	var execErr template.ExecError
+	if !errors.As(te, &execErr) {
+		t.Errorf("%v does not wrap a template.ExecError", te)
+	}
+}
@@ -0,0 +1,137 @@
+package craft
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feature is an optional part of a generated project, selected with
+// Data.Includes.
+type Feature struct {
+	Name        string
+	Description string
+	// Requires lists the features pulled in along with this one.
+	Requires []string
+}
+
+// Features lists every feature generators can be gated on.
+var Features = []Feature{
+	{Name: "cli", Description: "Command line entry points built on the selected CLI framework"},
+	{Name: "server", Description: "Server subcommand", Requires: []string{"cli"}},
+	{Name: "proto", Description: "Protocol buffers code generation task"},
+
+	{Name: "docker", Description: "Dockerfiles, Docker helper scripts and the main compose file"},
+	{Name: "mysql", Description: "MySQL compose service"},
+	{Name: "postgres", Description: "PostgreSQL compose service"},
+	{Name: "mariadb", Description: "MariaDB compose service"},
+	{Name: "redis", Description: "Redis compose service"},
+	{Name: "rabbitmq", Description: "RabbitMQ compose service"},
+	{Name: "grafana", Description: "Grafana compose service"},
+	{Name: "prometheus", Description: "Prometheus compose service"},
+	{Name: "jaeger", Description: "Jaeger compose service"},
+	{Name: "kafka", Description: "Kafka and ZooKeeper compose services"},
+	{Name: "minio", Description: "MinIO compose service"},
+	{Name: "localstack", Description: "LocalStack compose service"},
+
+	{Name: "k8s", Description: "Kubernetes manifests with Kustomize overlays"},
+	{Name: "helm", Description: "Helm chart"},
+	{Name: "terraform", Description: "Terraform configuration"},
+	{Name: "ansible", Description: "Ansible playbook"},
+	{Name: "swarm", Description: "Docker Swarm stack"},
+
+	{Name: "github", Description: "GitHub Actions workflow"},
+	{Name: "gitlab", Description: "GitLab CI pipeline"},
+	{Name: "taskfile", Description: "Taskfile for the task runner"},
+	{Name: "dagger", Description: "Dagger pipeline"},
+}
+
+// NoFeatures, as the only item of Data.Includes, includes no feature at
+// all, whereas an empty Includes includes every feature.
+const NoFeatures = "none"
+
+// LookupFeature returns the feature called name.
+func LookupFeature(name string) (Feature, bool) {
+	for _, f := range Features {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Feature{}, false
+}
+
+// checkFeatures fails on the first feature name that does not exist.
+func checkFeatures(names []string) error {
+	for _, name := range names {
+		if _, ok := LookupFeature(name); !ok {
+			return fmt.Errorf("unknown feature %q, available features: %s", name, strings.Join(featureNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+func featureNames() []string {
+	names := make([]string, 0, len(Features))
+	for _, f := range Features {
+		names = append(names, f.Name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// enabledFeatures returns the included features along with the features
+// they require.
+func (d Data) enabledFeatures() map[string]bool {
+	enabled := make(map[string]bool)
+
+	var enable func(name string)
+	enable = func(name string) {
+		if enabled[name] {
+			return
+		}
+		enabled[name] = true
+
+		if f, ok := LookupFeature(name); ok {
+			for _, r := range f.Requires {
+				enable(r)
+			}
+		}
+	}
+
+	for _, name := range d.Includes {
+		enable(name)
+	}
+
+	return enabled
+}
+
+// HasFeature reports whether the feature is part of the project. Every
+// feature is included when Includes is empty, and none when it only holds
+// NoFeatures.
+func (d Data) HasFeature(name string) bool {
+	if len(d.Includes) == 0 {
+		return true
+	}
+
+	return d.enabledFeatures()[name]
+}
+
+// hasAnyFeature reports whether an entry gated on features is generated.
+// Entries without features are always generated.
+func (d Data) hasAnyFeature(features []string) bool {
+	if len(features) == 0 {
+		return true
+	}
+
+	for _, f := range features {
+		if d.HasFeature(f) {
+			return true
+		}
+	}
+
+	return false
+}
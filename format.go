@@ -0,0 +1,123 @@
+package craft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+// formatGo tidies generated Go source: unused imports are dropped, the
+// remaining ones are grouped as standard library first and everything else
+// second, and the result is gofmt'ed. Missing imports are never added, so
+// that the output does not depend on the packages of the machine running
+// craft. Errors carry the file, line and column of the offending code.
+func formatGo(path string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) {
+			return nil, fmt.Errorf("generated Go code does not parse: %w", err)
+		}
+
+		return nil, fmt.Errorf("failed to parse generated Go code %s: %w", path, err)
+	}
+
+	removeUnusedImports(fset, file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to format generated Go code %s: %w", path, err)
+	}
+
+	out, err := imports.Process(path, buf.Bytes(), &imports.Options{
+		FormatOnly: true,
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated Go code %s: %w", path, err)
+	}
+
+	return out, nil
+}
+
+// removeUnusedImports deletes the imports of file none of whose identifiers
+// are referenced. Blank and dot imports are kept.
+func removeUnusedImports(fset *token.FileSet, file *ast.File) {
+	used := make(map[string]bool)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			// Package names are the identifiers the parser could not
+			// resolve to a declaration of the file.
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	// Deleting an import updates file.Imports.
+	specs := append([]*ast.ImportSpec{}, file.Imports...)
+
+	for _, spec := range specs {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		name := importName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		if name == "_" || name == "." || used[name] {
+			continue
+		}
+
+		if spec.Name != nil {
+			astutil.DeleteNamedImport(fset, file, spec.Name.Name, path)
+		} else {
+			astutil.DeleteImport(fset, file, path)
+		}
+	}
+}
+
+// importName guesses the name of the package imported as path, the way
+// goimports does: "gopkg.in/yaml.v3" is yaml and "github.com/urfave/cli/v2"
+// is cli.
+func importName(path string) string {
+	base := pathpkg.Base(path)
+
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil && pathpkg.Dir(path) != "." {
+			base = pathpkg.Base(pathpkg.Dir(path))
+		}
+	}
+
+	base = strings.TrimPrefix(base, "go-")
+
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		base = base[:i]
+	}
+
+	return base
+}
@@ -0,0 +1,228 @@
+package craft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatGo(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "versioned paths",
+			src: `package main
+
+import (
+	"github.com/urfave/cli/v2"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = yaml.Marshal
+var _ = cli.NewApp
+var _ = fmt.Sprint
+`,
+			want: `package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = yaml.Marshal
+var _ = cli.NewApp
+var _ = fmt.Sprint
+`,
+		},
+		{
+			name: "unused imports",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func main() { fmt.Println() }
+`,
+			want: `package main
+
+import (
+	"fmt"
+)
+
+func main() { fmt.Println() }
+`,
+		},
+		{
+			name: "named and blank imports",
+			src: `package main
+
+import (
+	_ "embed"
+	y "gopkg.in/yaml.v3"
+	unused "os"
+)
+
+var _ = y.Marshal
+`,
+			want: `package main
+
+import (
+	_ "embed"
+
+	y "gopkg.in/yaml.v3"
+)
+
+var _ = y.Marshal
+`,
+		},
+		{
+			// Adding imports would depend on the packages of the machine
+			// running craft.
+			name: "missing imports",
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println(strings.ToUpper("hi")) }
+`,
+			want: `package main
+
+import "fmt"
+
+func main() { fmt.Println(strings.ToUpper("hi")) }
+`,
+		},
+		{
+			name: "shadowed package name",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	var fmt struct{ Println func() }
+	fmt.Println()
+	os.Exit(0)
+}
+`,
+			want: `package main
+
+import (
+	"os"
+)
+
+func main() {
+	var fmt struct{ Println func() }
+	fmt.Println()
+	os.Exit(0)
+}
+`,
+		},
+		{
+			name: "comments",
+			src: `// Package main does things.
+package main
+
+import (
+	// fmt prints.
+	"fmt"
+	"os" // os exits.
+)
+
+// main runs.
+func main() {
+	fmt.Println() // print
+	os.Exit(0)
+}
+`,
+			want: `// Package main does things.
+package main
+
+import (
+	// fmt prints.
+	"fmt"
+	"os" // os exits.
+)
+
+// main runs.
+func main() {
+	fmt.Println() // print
+	os.Exit(0)
+}
+`,
+		},
+		{
+			name: "formatting",
+			src: `package main
+import "fmt"
+func main() {
+fmt.Println( "hi" )
+}
+`,
+			want: `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hi")
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGo("main.go", []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"fmt":                         "fmt",
+		"path/filepath":               "filepath",
+		"gopkg.in/yaml.v3":            "yaml",
+		"github.com/urfave/cli/v2":    "cli",
+		"github.com/mattn/go-isatty":  "isatty",
+		"github.com/example/app/v2x":  "v2x",
+		"dagger.io/dagger":            "dagger",
+		"universe.dagger.io/docker":   "docker",
+		"github.com/example/api-core": "api",
+	}
+
+	for path, want := range tests {
+		if got := importName(path); got != want {
+			t.Errorf("importName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFormatGoError(t *testing.T) {
+	_, err := formatGo("cmd/app/main.go", []byte("package main\n\nfunc main() {\n"))
+	if err == nil {
+		t.Fatal("got no error for invalid code")
+	}
+
+	if want := "cmd/app/main.go:3:"; !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q, want it to point at %s", err, want)
+	}
+}
@@ -0,0 +1,218 @@
+package craft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+// FuncMap returns the functions available to every template.
+//
+// The case helpers turn a name such as "http server", "http-server" or
+// "httpServer" into:
+//
+//   - ToPascal: HttpServer, for exported Go identifiers
+//   - ToCamel: httpServer, for unexported Go identifiers
+//   - ToLowerCamel: httpServer, the strcase name of ToCamel
+//   - ToSnake: http_server, for file names
+//   - ToKebab: http-server, for command and flag names
+//   - ToScreamingSnake: HTTP_SERVER, for environment variables
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"ToUpper": strings.ToUpper,
+		"ToLower": strings.ToLower,
+
+		"ToPascal":         strcase.ToCamel,
+		"ToCamel":          strcase.ToLowerCamel,
+		"ToLowerCamel":     strcase.ToLowerCamel,
+		"ToSnake":          strcase.ToSnake,
+		"ToKebab":          strcase.ToKebab,
+		"ToScreamingSnake": strcase.ToScreamingSnake,
+		"Pluralize":        pluralize,
+
+		"Indent":  indent,
+		"NIndent": nindent,
+		"Quote":   strconv.Quote,
+		"Join":    join,
+		"Split":   split,
+
+		"Default":  defaultValue,
+		"Required": required,
+
+		"ToYAML": toYAML,
+		"ToJSON": toJSON,
+
+		"SemverMajor":   semverMajor,
+		"SemverMinor":   semverMinor,
+		"SemverPatch":   semverPatch,
+		"SemverCompare": semverCompare,
+	}
+}
+
+// pluralize returns the English plural of a singular noun.
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// indent prefixes every line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// nindent is like indent, but starts with a newline.
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
+
+// join takes the separator first so that it can be used in pipelines.
+func join(sep string, elems []string) string {
+	return strings.Join(elems, sep)
+}
+
+// split takes the separator first so that it can be used in pipelines.
+func split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// defaultValue returns value, or def when value is empty.
+func defaultValue(def, value interface{}) interface{} {
+	if isEmpty(value) {
+		return def
+	}
+
+	return value
+}
+
+// required fails rendering with msg when value is empty.
+func required(msg string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, errors.New(msg)
+	}
+
+	return value, nil
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func toYAML(value interface{}) (string, error) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// parseSemver splits a version such as 1.21, v1.21.3 or 1.21.3-rc.1 into
+// its major, minor and patch numbers.
+func parseSemver(version string) ([3]int, error) {
+	var parts [3]int
+
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return parts, fmt.Errorf("invalid version %q", version)
+	}
+
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version %q", version)
+		}
+
+		parts[i] = n
+	}
+
+	return parts, nil
+}
+
+func semverMajor(version string) (int, error) {
+	parts, err := parseSemver(version)
+
+	return parts[0], err
+}
+
+func semverMinor(version string) (int, error) {
+	parts, err := parseSemver(version)
+
+	return parts[1], err
+}
+
+func semverPatch(version string) (int, error) {
+	parts, err := parseSemver(version)
+
+	return parts[2], err
+}
+
+// semverCompare returns -1, 0 or 1 depending on whether a is lower than,
+// equal to or greater than b.
+func semverCompare(a, b string) (int, error) {
+	pa, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+
+	pb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,105 @@
+package craft_test
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func execute(t *testing.T, text string, data any) (string, error) {
+	t.Helper()
+
+	tpl, err := template.New("test").Funcs(craft.FuncMap()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = tpl.Execute(&out, data)
+
+	return out.String(), err
+}
+
+func TestFuncMapCase(t *testing.T) {
+	funcs := []struct {
+		name string
+		want string
+	}{
+		{name: "ToPascal", want: "HttpServer"},
+		{name: "ToCamel", want: "httpServer"},
+		{name: "ToLowerCamel", want: "httpServer"},
+		{name: "ToSnake", want: "http_server"},
+		{name: "ToKebab", want: "http-server"},
+		{name: "ToScreamingSnake", want: "HTTP_SERVER"},
+	}
+
+	for _, f := range funcs {
+		for _, input := range []string{"http server", "http-server", "http_server", "httpServer"} {
+			t.Run(f.name+"/"+input, func(t *testing.T) {
+				got, err := execute(t, "{{"+f.name+" .}}", input)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got != f.want {
+					t.Errorf("got %q, want %q", got, f.want)
+				}
+			})
+		}
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	tests := []struct {
+		text string
+		data any
+		want string
+	}{
+		{text: `{{Pluralize "command"}} {{Pluralize "process"}} {{Pluralize "policy"}} {{Pluralize "key"}}`, want: "commands processes policies keys"},
+		{text: `{{"a\nb" | Indent 2}}`, want: "  a\n  b"},
+		{text: `x:{{"a" | NIndent 2}}`, want: "x:\n  a"},
+		{text: `{{Quote "a\"b"}}`, want: `"a\"b"`},
+		{text: `{{. | Join ", "}}`, data: []string{"a", "b"}, want: "a, b"},
+		{text: `{{range Split "," "a,b"}}[{{.}}]{{end}}`, want: "[a][b]"},
+		{text: `{{Default "8080" .}}`, data: "", want: "8080"},
+		{text: `{{Default "8080" .}}`, data: "9090", want: "9090"},
+		{text: `{{ToYAML .}}`, data: map[string]int{"port": 8080}, want: "port: 8080"},
+		{text: `{{ToJSON .}}`, data: []string{"a"}, want: `["a"]`},
+		{text: `{{SemverMajor "v1.21.3"}}.{{SemverMinor "1.21"}}.{{SemverPatch "1.21.3-rc.1"}}`, want: "1.21.3"},
+		{text: `{{SemverCompare "1.21" "1.9"}} {{SemverCompare "1.21.0" "1.21"}}`, want: "1 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := execute(t, tt.text, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncMapErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: `{{Required "a module path is required" .}}`, want: "a module path is required"},
+		{text: `{{SemverMajor "one"}}`, want: `invalid version "one"`},
+		{text: `{{SemverCompare "1.2.3.4" "1"}}`, want: `invalid version "1.2.3.4"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if _, err := execute(t, tt.text, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
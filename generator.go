@@ -3,161 +3,467 @@ package craft
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
 	"log"
 	"path/filepath"
-	"reflect"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 )
 
 type CLI struct {
-	Framework string
+	Framework string `json:"framework"`
 }
 type Data struct {
 	CLI
-	Binaries []string
-	License  string
-
-	ProjectName  string
-	ModulePrefix string
-	Includes     []string
-	GoVersion    string
-	Author       string
-	ConfigDirs   []string
-	ConfigFile   string
-	ConfigFormat string
-	EnvPrefix    string
-	Module       string
-	AppName      string
-	Description  string
-	Commands     []string
+	Binaries []string `json:"binaries"`
+	License  string   `json:"license"`
+
+	ProjectName  string    `json:"project_name"`
+	ModulePrefix string    `json:"module_prefix"`
+	Includes     []string  `json:"includes"`
+	GoVersion    string    `json:"go_version"`
+	Author       string    `json:"author"`
+	ConfigDirs   []string  `json:"config_dirs"`
+	ConfigFile   string    `json:"config_file"`
+	ConfigFormat string    `json:"config_format"`
+	EnvPrefix    string    `json:"env_prefix"`
+	Module       string    `json:"module"`
+	AppName      string    `json:"app_name"`
+	Description  string    `json:"description"`
+	Commands     []Command `json:"commands"`
+}
+
+// TemplateData is the data a file is rendered with. It is implemented by
+// Data, and so by the types embedding it such as CommandOptions, and by
+// PluginData.
+type TemplateData interface {
+	templateData()
 }
 
 type RenderOptions struct {
 	Templates []string
-	Data      interface{}
-	Execute   string
+	Data      TemplateData
+	// Execute names the template, among those defined by Templates, that
+	// renders the file. It may be left empty when there is a single
+	// template, which is then executed.
+	Execute string
+
+	// Mode is the permission of the generated file, DefaultFileMode when
+	// zero.
+	Mode fs.FileMode
+	// CreateOnly marks files that are written only when they do not exist
+	// yet, leaving an existing copy untouched.
+	CreateOnly bool
+	// Features gates the file on the project including at least one of
+	// them. Files without features are always generated.
+	Features []string
+	// Content, when set, is written as is instead of rendering Templates.
+	Content []byte
 }
 
-// Generator interface with Configure and Generate methods
-type Generator func(data Data) (map[string]RenderOptions, error)
+// entry returns the name of the template rendering the file, failing when
+// it is ambiguous or not defined by the parsed templates.
+func (o RenderOptions) entry(tpl *template.Template) (string, error) {
+	entry := o.Execute
+	if entry == "" {
+		if len(o.Templates) != 1 {
+			return "", fmt.Errorf("%d templates given without the one to execute", len(o.Templates))
+		}
+
+		entry = o.Templates[0]
+	}
+
+	if tpl.Lookup(entry) == nil {
+		return "", fmt.Errorf("template %q to execute is not defined by %s", entry, strings.Join(o.Templates, ", "))
+	}
 
+	return entry, nil
+}
+
+// DefaultFileMode is the permission of generated files that do not set one.
+const DefaultFileMode fs.FileMode = 0644
+
+// File is a generated file along with the generator and templates that
+// produced it. When the templates come from a LayeredFS, Layers holds the
+// layer each template was resolved from.
+type File struct {
+	Path       string
+	Generator  string
+	Templates  []string
+	Layers     []string
+	Content    []byte
+	Mode       fs.FileMode
+	CreateOnly bool
+}
+
+// Generator produces the files of one part of a project.
+type Generator interface {
+	// Name identifies the generator, e.g. on the command line.
+	Name() string
+	Description() string
+	// Features gates the generator on the project including at least one
+	// of them. Generators without features always run.
+	Features() []string
+	// Dependencies names the generators that must run along with, and
+	// before, this one.
+	Dependencies() []string
+	// Validate reports whether the generator can run with data.
+	Validate(data Data) error
+	Generate(data Data) (map[string]RenderOptions, error)
+}
+
+// Manager renders the files of the generators in Registry. Templates are
+// parsed once per Manager and reused across files and calls; use Configure
+// to change the Options afterwards.
 type Manager struct {
-	Options    Options
-	Generators map[string]Generator
+	Options  Options
+	Registry *Registry
+
+	cache templateCache
 }
 
 func (g *Manager) Configure(options Options) error {
 	g.Options = options
+	g.cache.reset()
 	return nil
 }
 
-func (g *Manager) generateFiles(ctx context.Context, m map[string]RenderOptions) (map[string][]byte, error) {
-	generatedFiles := make(map[string][]byte)
+// generateFiles renders every entry of m on a pool of Options.Concurrency
+// workers. Rendering goes on after a failure, so that the returned Errors
+// list every broken template at once, but stops when ctx is cancelled.
+func (g *Manager) generateFiles(ctx context.Context, m map[string]RenderOptions) (map[string]File, error) {
+	dsts := make([]string, 0, len(m))
+	for dst := range m {
+		dsts = append(dsts, dst)
+	}
+	sort.Strings(dsts)
+
+	type result struct {
+		file File
+		err  error
+	}
 
-	for dst, opts := range m {
-		tpl := template.New(dst)
-		tpl.Funcs(template.FuncMap{
-			"ToUpper": strings.ToUpper,
-		})
+	results := make([]result, len(dsts))
+	jobs := make(chan int)
 
-		for _, tmpl := range opts.Templates {
-			tmplPath := filepath.Join("templates", tmpl)
+	var wg sync.WaitGroup
 
-			tmplFile, err := g.Options.Templates.Open(tmplPath)
-			if err != nil {
-				log.Printf("Error opening template %s: %v", tmplPath, err)
+	for i := 0; i < g.Options.concurrency(); i++ {
+		wg.Add(1)
 
-				return nil, fmt.Errorf("failed to open template %s: %w", tmplPath, err)
-			}
+		go func() {
+			defer wg.Done()
 
-			content, err := io.ReadAll(tmplFile)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read template %s: %v", tmplPath, err)
+			for i := range jobs {
+				file, err := g.renderFile(ctx, dsts[i], m[dsts[i]])
+				results[i] = result{file: file, err: err}
 			}
+		}()
+	}
+
+feed:
+	for i := range dsts {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	generatedFiles := make(map[string]File, len(dsts))
+
+	var errs Errors
+
+	for i, dst := range dsts {
+		if results[i].err != nil {
+			errs = append(errs, flattenErrors(results[i].err)...)
+			continue
+		}
+
+		generatedFiles[dst] = results[i].file
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return generatedFiles, nil
+}
+
+// renderFile renders a single entry of a generator.
+func (g *Manager) renderFile(ctx context.Context, dst string, opts RenderOptions) (File, error) {
+	mode := opts.Mode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+
+	if opts.Content != nil {
+		return File{
+			Path:       dst,
+			Content:    opts.Content,
+			Mode:       mode,
+			CreateOnly: opts.CreateOnly,
+		}, nil
+	}
+
+	if len(opts.Templates) == 0 {
+		return File{}, &TemplateError{Dst: dst, Err: errors.New("no template to render")}
+	}
 
-			tpl, err = tpl.Parse(string(content))
+	set := g.cache.get(opts.Templates, g.parseTemplates)
+	if set.err != nil {
+		return File{}, set.errorFor(dst)
+	}
+
+	entry, err := opts.entry(set.tpl)
+	if err != nil {
+		return File{}, &TemplateError{Dst: dst, Err: err}
+	}
+
+	content, err := g.generateFile(ctx, dst, set.tpl, entry, opts.Data)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{
+		Path:       dst,
+		Templates:  opts.Templates,
+		Layers:     set.layers,
+		Content:    content,
+		Mode:       mode,
+		CreateOnly: opts.CreateOnly,
+	}, nil
+}
+
+// parseTemplates parses templates into a single set, each under its own
+// path, and returns the layer every template was resolved from.
+func (g *Manager) parseTemplates(templates []string) (*template.Template, []string, error) {
+	tpl := template.New("")
+	tpl.Funcs(FuncMap())
+
+	var (
+		layers []string
+		errs   Errors
+	)
+
+	for _, tmplPath := range templates {
+		if resolver, ok := g.Options.Templates.(layerResolver); ok {
+			layer, err := resolver.Layer(tmplPath)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse template %s: %w", tmplPath, err)
+				errs = append(errs, &TemplateError{Template: tmplPath, Err: err})
+				continue
 			}
+
+			layers = append(layers, layer)
 		}
 
-		content, err := g.generateFile(ctx, dst, tpl, opts.Data)
+		content, err := fs.ReadFile(g.Options.Templates, tmplPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate file for template %s: %w", opts, err)
+			errs = append(errs, &TemplateError{Template: tmplPath, Err: err})
+			continue
 		}
-		for k, v := range content {
-			generatedFiles[k] = v
+
+		if _, err := tpl.New(tmplPath).Parse(string(content)); err != nil {
+			errs = append(errs, newTemplateError(tmplPath, "", err))
 		}
 	}
 
-	return generatedFiles, nil
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	return tpl, layers, nil
 }
 
-func (g *Manager) generateFile(_ context.Context, dst string, template *template.Template, data interface{}) (map[string][]byte, error) {
+func (g *Manager) generateFile(_ context.Context, dst string, template *template.Template, entry string, data TemplateData) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 
-	// Use reflection to check if the data has an "Execute" field
-	v := reflect.ValueOf(data)
-	executeField := v.FieldByName("Execute")
+	if err := template.ExecuteTemplate(buf, entry, data); err != nil {
+		return nil, newTemplateError(entry, dst, err)
+	}
 
-	if executeField.IsValid() && executeField.Kind() == reflect.String && executeField.String() != "" {
-		executeTemplateName := executeField.String()
-		if err := template.ExecuteTemplate(buf, executeTemplateName, data); err != nil {
-			return nil, fmt.Errorf("failed to execute template %s: %w", dst, err)
-		}
-	} else {
-		if err := template.Execute(buf, data); err != nil {
-			return nil, fmt.Errorf("failed to execute template %s: %w", dst, err)
+	content := buf.Bytes()
+
+	if filepath.Ext(dst) == ".go" {
+		formatted, err := formatGo(dst, content)
+		if err != nil {
+			return nil, err
 		}
+
+		content = formatted
 	}
 
-	// Return the generated content in a map with the destination as the key
-	return map[string][]byte{
-		dst: buf.Bytes(),
-	}, nil
+	return content, nil
 }
 
 // Options struct to hold configuration parameters, including the file system
+// the templates are read from, rooted at the templates directory.
 type Options struct {
 	Templates fs.FS
+	// Concurrency bounds the number of files rendered at once. It defaults
+	// to GOMAXPROCS.
+	Concurrency int
+	// Logger, when set, reports the generators Render runs and skips.
+	Logger *log.Logger
+}
+
+func (o Options) logf(format string, args ...any) {
+	if o.Logger != nil {
+		o.Logger.Printf(format, args...)
+	}
+}
+
+func (o Options) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+
+	return runtime.GOMAXPROCS(0)
 }
 
-// ScriptGenerator struct
-type ScriptGenerator struct {
-	Manager
+// layerResolver is implemented by template filesystems that can tell where a
+// template comes from, such as LayeredFS.
+type layerResolver interface {
+	Layer(name string) (string, error)
+}
+
+// contextGenerator is implemented by generators that can be cancelled, such
+// as plugins, which get the context of Render instead of Validate and
+// Generate.
+type contextGenerator interface {
+	ValidateContext(ctx context.Context, data Data) error
+	GenerateContext(ctx context.Context, data Data) (map[string]RenderOptions, error)
+}
+
+func validateGenerator(ctx context.Context, g Generator, data Data) error {
+	if cg, ok := g.(contextGenerator); ok {
+		return cg.ValidateContext(ctx, data)
+	}
+
+	return g.Validate(data)
+}
+
+func runGenerator(ctx context.Context, g Generator, data Data) (map[string]RenderOptions, error) {
+	if cg, ok := g.(contextGenerator); ok {
+		return cg.GenerateContext(ctx, data)
+	}
+
+	return g.Generate(data)
 }
 
 func (m *Manager) Generate(ctx context.Context, data Data, generators ...string) (map[string][]byte, error) {
-	generatedFiles := make(map[string][]byte)
+	files, err := m.Render(ctx, data, generators...)
+	if err != nil {
+		return nil, err
+	}
 
-	for name, generator := range m.Generators {
-		log.Println("Generating", name)
+	generatedFiles := make(map[string][]byte, len(files))
+	for k, v := range files {
+		generatedFiles[k] = v.Content
+	}
 
-		// Check if the generator is in the provided list
-		if !contains(generators, name) {
-			continue
+	return generatedFiles, nil
+}
+
+// Render runs the selected generators like Generate, but keeps track of the
+// generator and templates behind every file.
+func (m *Manager) Render(ctx context.Context, data Data, generators ...string) (map[string]File, error) {
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	run, skipped, err := m.Registry.Resolve(data, generators...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range skipped {
+		m.Options.logf("Skipping %s: %s", s.Generator, s.Reason)
+	}
+
+	var errs Errors
+
+	for _, generator := range run {
+		if err := validateGenerator(ctx, generator, data); err != nil {
+			errs = append(errs, fmt.Errorf("generator %s: %w", generator.Name(), err))
 		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	mappings := make(map[string]RenderOptions)
+	owners := make(map[string]string)
 
-		mapping, err := generator(data)
+	for _, generator := range run {
+		name := generator.Name()
+
+		m.Options.logf("Generating %s", name)
+
+		mapping, err := runGenerator(ctx, generator, data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get mapping: %w", err)
 		}
 
-		files, err := m.generateFiles(ctx, mapping)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate files: %w", err)
+		dsts := make([]string, 0, len(mapping))
+		for dst := range mapping {
+			dsts = append(dsts, dst)
 		}
+		sort.Strings(dsts)
 
-		for k, v := range files {
-			generatedFiles[k] = v
+		for _, dst := range dsts {
+			opts := mapping[dst]
+			if !data.hasAnyFeature(opts.Features) {
+				continue
+			}
+
+			if prev, ok := owners[dst]; ok {
+				errs = append(errs, fmt.Errorf("%s is generated by both %s and %s", dst, prev, name))
+				continue
+			}
+
+			mappings[dst] = opts
+			owners[dst] = name
 		}
 	}
 
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	generatedFiles, err := m.generateFiles(ctx, mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range generatedFiles {
+		v.Generator = owners[k]
+		generatedFiles[k] = v
+	}
+
+	contents := make(map[string][]byte, len(generatedFiles))
+	for k, v := range generatedFiles {
+		contents[k] = v.Content
+	}
+
+	if err := Validate(contents); err != nil {
+		return nil, fmt.Errorf("generated files are invalid: %w", err)
+	}
+
 	return generatedFiles, nil
 }
 
@@ -171,6 +477,8 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+func (Data) templateData() {}
+
 func (d Data) Year() string {
 	return fmt.Sprintf("%d", time.Now().Year())
 }
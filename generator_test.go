@@ -0,0 +1,231 @@
+package craft_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/edsonmichaque/craft"
+	"github.com/edsonmichaque/craft/crafttest"
+)
+
+func testData() craft.Data {
+	return craft.Data{
+		ProjectName:  "example",
+		ModulePrefix: "github.com/example/example",
+		Module:       "github.com/example/example",
+		AppName:      "example",
+		Description:  "example",
+		GoVersion:    "1.21",
+		Author:       "Jane Doe",
+		License:      "mit",
+		ConfigDirs:   []string{"/etc/example", "$HOME/.config/example"},
+		ConfigFile:   "config.yml",
+		ConfigFormat: "yml",
+		EnvPrefix:    "EXAMPLE",
+		Binaries:     []string{"example"},
+		Includes:     []string{},
+		Commands:     []craft.Command{},
+		CLI:          craft.CLI{Framework: "cobra"},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type testCase struct {
+		name       string
+		data       craft.Data
+		generators []string
+	}
+
+	var tests []testCase
+
+	for _, framework := range craft.CLIFrameworks {
+		single := testData()
+		single.Framework = framework
+
+		multi := single
+		multi.Binaries = []string{"examplectl", "exampled"}
+		multi.Commands = []craft.Command{
+			{Binary: "examplectl", Name: "migrate", Flags: []craft.CommandFlag{{Name: "dry-run", Type: "bool"}, {Name: "timeout", Type: "duration"}}},
+			{Binary: "examplectl", Parent: "migrate", Name: "up"},
+			{Binary: "exampled", Parent: "server", Name: "reload"},
+		}
+
+		tests = append(tests,
+			testCase{name: "single-" + framework, data: single},
+			testCase{name: "multi-" + framework, data: multi},
+		)
+	}
+
+	licenses := make([]string, 0, len(craft.Licenses))
+	for name := range craft.Licenses {
+		licenses = append(licenses, name)
+	}
+	sort.Strings(licenses)
+
+	for _, license := range licenses {
+		data := testData()
+		data.License = license
+
+		tests = append(tests, testCase{name: "license-" + license, data: data, generators: []string{"license"}})
+	}
+
+	// The license templates print the current year.
+	year := []byte(strconv.Itoa(time.Now().Year()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := craft.Manager{
+				Registry: craft.DefaultRegistry(),
+				Options: craft.Options{
+					Templates: os.DirFS(filepath.Join("cmd", "craft", "templates")),
+				},
+			}
+
+			generators := tt.generators
+			if generators == nil {
+				generators = manager.Registry.Names()
+			}
+
+			files, err := manager.Generate(context.Background(), tt.data, generators...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for path, content := range files {
+				files[path] = bytes.ReplaceAll(content, year, []byte("YEAR"))
+			}
+
+			crafttest.Golden(t, filepath.Join("testdata", tt.name+".txtar"), files)
+		})
+	}
+}
+
+func TestRenderLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	manager := craft.Manager{
+		Registry: craft.DefaultRegistry(),
+		Options: craft.Options{
+			Templates: os.DirFS(filepath.Join("cmd", "craft", "templates")),
+			Logger:    log.New(&buf, "", 0),
+		},
+	}
+
+	if _, err := manager.Render(context.Background(), testData(), "license"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "Generating license\n") {
+		t.Errorf("log does not report the license generator:\n%s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "Skipping commands: not selected\n") {
+		t.Errorf("log does not report the skipped commands generator:\n%s", buf.String())
+	}
+}
+
+// probeData records how many files render at once through its Enter
+// method, which runs hook first when set.
+type probeData struct {
+	craft.Data
+
+	mu      sync.Mutex
+	active  int
+	max     int
+	entered int
+	hook    func()
+}
+
+func (p *probeData) Enter() string {
+	p.mu.Lock()
+	p.active++
+	p.entered++
+	p.max = max(p.max, p.active)
+	hook := p.hook
+	p.mu.Unlock()
+
+	if hook != nil {
+		hook()
+	}
+
+	time.Sleep(time.Millisecond)
+
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+
+	return "ok"
+}
+
+func probeManager(t *testing.T, probe *probeData, n, concurrency int) *craft.Manager {
+	t.Helper()
+
+	registry, err := craft.NewRegistry(craft.NewGenerator("probe", "Probe", func(craft.Data) (map[string]craft.RenderOptions, error) {
+		out := make(map[string]craft.RenderOptions, n)
+		for i := 0; i < n; i++ {
+			out[fmt.Sprintf("file%03d.txt", i)] = craft.RenderOptions{Templates: []string{"probe.tmpl"}, Data: probe}
+		}
+
+		return out, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &craft.Manager{
+		Registry: registry,
+		Options: craft.Options{
+			Templates:   fstest.MapFS{"probe.tmpl": {Data: []byte("{{.Enter}}")}},
+			Concurrency: concurrency,
+		},
+	}
+}
+
+func TestRenderConcurrency(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		t.Run(strconv.Itoa(concurrency), func(t *testing.T) {
+			probe := &probeData{Data: testData()}
+
+			files, err := probeManager(t, probe, 30, concurrency).Render(context.Background(), testData(), "probe")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(files) != 30 {
+				t.Errorf("got %d files, want 30", len(files))
+			}
+
+			if probe.max > concurrency {
+				t.Errorf("got %d files rendered at once, want at most %d", probe.max, concurrency)
+			}
+		})
+	}
+}
+
+func TestRenderCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	probe := &probeData{Data: testData(), hook: cancel}
+
+	_, err := probeManager(t, probe, 100, 1).Render(ctx, testData(), "probe")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if probe.entered == 100 {
+		t.Error("every file rendered after the context was cancelled")
+	}
+}
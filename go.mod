@@ -2,4 +2,22 @@ module github.com/edsonmichaque/craft
 
 go 1.23.3
 
-require github.com/iancoleman/strcase v0.3.0
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/hashicorp/hcl/v2 v2.23.0
+	github.com/iancoleman/strcase v0.3.0
+	golang.org/x/mod v0.18.0
+	golang.org/x/tools v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+	mvdan.cc/sh/v3 v3.10.0
+)
+
+require (
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
+	github.com/zclconf/go-cty v1.13.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+)
@@ -0,0 +1,88 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// Layer is a named template filesystem rooted at the templates directory.
+type Layer struct {
+	Name string
+	FS   fs.FS
+}
+
+// LayeredFS is an fs.FS made of several layers searched in order, so that
+// a template found in an earlier layer overrides the same path in the
+// layers after it.
+type LayeredFS []Layer
+
+// Open opens name from the first layer that contains it.
+func (l LayeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range l {
+		f, err := layer.FS.Open(name)
+		if err == nil {
+			return f, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists the union of the entries of name in every layer.
+func (l LayeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	found := false
+
+	var entries []fs.DirEntry
+
+	for _, layer := range l {
+		layerEntries, err := fs.ReadDir(layer.FS, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+
+		found = true
+
+		for _, e := range layerEntries {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// Layer returns the name of the layer name is resolved from.
+func (l LayeredFS) Layer(name string) (string, error) {
+	for _, layer := range l {
+		_, err := fs.Stat(layer.FS, name)
+		if err == nil {
+			return layer.Name, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+	}
+
+	return "", &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,119 @@
+package craft_test
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func testLayers() craft.LayeredFS {
+	return craft.LayeredFS{
+		{Name: "project", FS: fstest.MapFS{
+			"common/readme.md.tmpl": {Data: []byte("project readme")},
+		}},
+		{Name: "user", FS: fstest.MapFS{
+			"common/readme.md.tmpl":  {Data: []byte("user readme")},
+			"common/gitignore.tmpl":  {Data: []byte("user gitignore")},
+			"docker/dockerfile.tmpl": {Data: []byte("user dockerfile")},
+		}},
+		{Name: "embedded", FS: fstest.MapFS{
+			"common/readme.md.tmpl": {Data: []byte("embedded readme")},
+			"common/gitignore.tmpl": {Data: []byte("embedded gitignore")},
+			"common/go.mod.tmpl":    {Data: []byte("embedded go.mod")},
+		}},
+	}
+}
+
+func TestLayeredFSOpen(t *testing.T) {
+	layers := testLayers()
+
+	for name, want := range map[string]string{
+		"common/readme.md.tmpl":  "project readme",
+		"common/gitignore.tmpl":  "user gitignore",
+		"common/go.mod.tmpl":     "embedded go.mod",
+		"docker/dockerfile.tmpl": "user dockerfile",
+	} {
+		got, err := fs.ReadFile(layers, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := layers.Open("common/missing.tmpl"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestLayeredFSReadDir(t *testing.T) {
+	layers := testLayers()
+
+	entries, err := fs.ReadDir(layers, "common")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	if want := []string{"gitignore.tmpl", "go.mod.tmpl", "readme.md.tmpl"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got entries %v, want %v", names, want)
+	}
+
+	// The directories of every layer are merged.
+	var files []string
+	err = fs.WalkDir(layers, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"common/gitignore.tmpl", "common/go.mod.tmpl", "common/readme.md.tmpl", "docker/dockerfile.tmpl"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("got files %v, want %v", files, want)
+	}
+
+	if _, err := fs.ReadDir(layers, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestLayeredFSLayer(t *testing.T) {
+	layers := testLayers()
+
+	for name, want := range map[string]string{
+		"common/readme.md.tmpl":  "project",
+		"common/gitignore.tmpl":  "user",
+		"common/go.mod.tmpl":     "embedded",
+		"docker/dockerfile.tmpl": "user",
+	} {
+		got, err := layers.Layer(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if got != want {
+			t.Errorf("%s: got layer %s, want %s", name, got, want)
+		}
+	}
+
+	if _, err := layers.Layer("common/missing.tmpl"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
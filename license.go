@@ -2,29 +2,45 @@ package craft
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 )
 
-func GenerateLicense(data Data) (map[string]RenderOptions, error) {
-	licenseTemplates := map[string]string{
-		"mit":          "license/mit.tmpl",
-		"apache-2.0":   "license/apache2.tmpl",
-		"agpl-3.0":     "license/agpl3.tmpl",
-		"bsd-3-clause": "license/bsd3.tmpl",
-		"gpl-3.0":      "license/gpl3.tmpl",
-		"mpl-2.0":      "license/mpl2.tmpl",
-		"apache":       "license/apache2.tmpl",
-		"agpl":         "license/agpl3.tmpl",
-		"bsd":          "license/bsd3.tmpl",
-		"gpl":          "license/gpl3.tmpl",
-		"mpl":          "license/mpl2.tmpl",
+// Licenses maps the accepted license names to their template.
+var Licenses = map[string]string{
+	"mit":          "license/mit.tmpl",
+	"apache-2.0":   "license/apache2.tmpl",
+	"agpl-3.0":     "license/agpl3.tmpl",
+	"bsd-3-clause": "license/bsd3.tmpl",
+	"gpl-3.0":      "license/gpl3.tmpl",
+	"mpl-2.0":      "license/mpl2.tmpl",
+	"apache":       "license/apache2.tmpl",
+	"agpl":         "license/agpl3.tmpl",
+	"bsd":          "license/bsd3.tmpl",
+	"gpl":          "license/gpl3.tmpl",
+	"mpl":          "license/mpl2.tmpl",
+}
+
+func validateLicense(data Data) error {
+	if _, exists := Licenses[data.License]; !exists {
+		names := make([]string, 0, len(Licenses))
+		for name := range Licenses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return fmt.Errorf("unknown license %q, expected one of %s", data.License, strings.Join(names, ", "))
 	}
 
-	templateFile, exists := licenseTemplates[data.License]
-	if !exists {
-		return nil, fmt.Errorf("license %s not found", data.License)
+	return nil
+}
+
+func GenerateLicense(data Data) (map[string]RenderOptions, error) {
+	if err := validateLicense(data); err != nil {
+		return nil, err
 	}
 
 	return map[string]RenderOptions{
-		"LICENSE": renderOptions(data, templateFile),
+		"LICENSE": renderOptions(data, Licenses[data.License]),
 	}, nil
 }
@@ -0,0 +1,174 @@
+package craft
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// LintKind classifies a LintIssue.
+type LintKind string
+
+const (
+	// LintMissing is a template referenced by a generator that does not
+	// exist.
+	LintMissing LintKind = "missing"
+	// LintOrphan is a template no generator references.
+	LintOrphan LintKind = "orphan"
+	// LintParse is a template that does not parse.
+	LintParse LintKind = "parse"
+)
+
+// LintIssue is a problem found by Lint.
+type LintIssue struct {
+	Kind     LintKind
+	Template string
+	// Generators lists the generators referencing a missing template.
+	Generators []string
+	Err        error
+}
+
+func (i LintIssue) String() string {
+	switch i.Kind {
+	case LintMissing:
+		return fmt.Sprintf("%s: missing template referenced by %s", i.Template, strings.Join(i.Generators, ", "))
+	case LintOrphan:
+		return fmt.Sprintf("%s: not referenced by any generator", i.Template)
+	default:
+		return i.Err.Error()
+	}
+}
+
+// lintSamples returns the project data the generators are run with to
+// collect their template references, covering the CLI frameworks, the
+// licenses, the binary layouts and added commands.
+func lintSamples() []Data {
+	base := Data{
+		ProjectName:  "lint",
+		ModulePrefix: "example.com/lint",
+		Module:       "example.com/lint",
+		AppName:      "lint",
+		GoVersion:    "1.21",
+		ConfigFile:   "config.yml",
+		ConfigFormat: "yml",
+		EnvPrefix:    "LINT",
+		License:      "mit",
+	}
+
+	binaries := [][]string{nil, {"lint"}, {"lint", "lintd"}}
+
+	var samples []Data
+
+	for _, framework := range CLIFrameworks {
+		for _, bins := range binaries {
+			d := base
+			d.Framework = framework
+			d.Binaries = bins
+
+			if bins != nil {
+				d.Commands = []Command{{Binary: bins[0], Name: "check"}}
+			}
+
+			samples = append(samples, d)
+		}
+	}
+
+	licenses := make([]string, 0, len(Licenses))
+	for name := range Licenses {
+		licenses = append(licenses, name)
+	}
+	sort.Strings(licenses)
+
+	for _, license := range licenses {
+		d := base
+		d.Framework = CLIFrameworks[0]
+		d.License = license
+		samples = append(samples, d)
+	}
+
+	return samples
+}
+
+// Lint cross-checks the generators of registry against the templates in
+// fsys. It reports, sorted by template, the templates referenced but
+// missing, the templates no generator references and the templates that do
+// not parse. Every feature is enabled while collecting references.
+func Lint(fsys fs.FS, registry *Registry) ([]LintIssue, error) {
+	referenced := make(map[string]map[string]bool)
+
+	for _, data := range lintSamples() {
+		for _, name := range registry.Names() {
+			g, _ := registry.Get(name)
+			if g.Validate(data) != nil {
+				continue
+			}
+
+			mapping, err := g.Generate(data)
+			if err != nil {
+				return nil, fmt.Errorf("generator %s: %w", name, err)
+			}
+
+			for _, opts := range mapping {
+				for _, tmpl := range opts.Templates {
+					if referenced[tmpl] == nil {
+						referenced[tmpl] = make(map[string]bool)
+					}
+
+					referenced[tmpl][name] = true
+				}
+			}
+		}
+	}
+
+	var issues []LintIssue
+
+	for tmpl, generators := range referenced {
+		if _, err := fs.Stat(fsys, tmpl); err == nil {
+			continue
+		}
+
+		names := make([]string, 0, len(generators))
+		for name := range generators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		issues = append(issues, LintIssue{Kind: LintMissing, Template: tmpl, Generators: names})
+	}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if referenced[path] == nil {
+			issues = append(issues, LintIssue{Kind: LintOrphan, Template: path})
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		if _, err := template.New(path).Funcs(FuncMap()).Parse(string(content)); err != nil {
+			issues = append(issues, LintIssue{Kind: LintParse, Template: path, Err: newTemplateError(path, "", err)})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Template != issues[j].Template {
+			return issues[i].Template < issues[j].Template
+		}
+
+		return issues[i].Kind < issues[j].Kind
+	})
+
+	return issues, nil
+}
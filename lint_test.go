@@ -0,0 +1,22 @@
+package craft_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// TestLint keeps the embedded templates in line with the built-in
+// generators: none missing, none unused and all parsing.
+func TestLint(t *testing.T) {
+	issues, err := craft.Lint(os.DirFS(filepath.Join("cmd", "craft", "templates")), craft.DefaultRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, issue := range issues {
+		t.Errorf("%s %s", issue.Kind, issue)
+	}
+}
@@ -0,0 +1,165 @@
+package craft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Version is the craft release recorded in lock files. It is set at build
+// time with -ldflags "-X github.com/edsonmichaque/craft.Version=...".
+var Version = "dev"
+
+// LockFileName is the name of the manifest written at the root of every
+// generated project.
+const LockFileName = ".craft.lock"
+
+// BaseDir is the directory of the project keeping a copy of every file as
+// craft generated it, under the same path. They are the merge bases of
+// craft update, and the lock only records their hashes.
+const BaseDir = ".craft/base"
+
+// Lock records everything craft produced for a project, so that later runs
+// can tell pristine scaffolding apart from files edited by hand.
+type Lock struct {
+	Version string `json:"version"`
+	Data    Data   `json:"data"`
+	// Generators lists the generators selected for the project. Every
+	// registered generator is selected when it is empty.
+	Generators []string     `json:"generators,omitempty"`
+	Files      []LockedFile `json:"files"`
+}
+
+// LockedFile describes a single generated file. Hash is the hash of the
+// generated content, which is kept under BaseDir.
+type LockedFile struct {
+	Path      string      `json:"path"`
+	Generator string      `json:"generator"`
+	Templates []string    `json:"templates"`
+	Layers    []string    `json:"layers,omitempty"`
+	Hash      string      `json:"hash"`
+	Mode      fs.FileMode `json:"mode,omitempty"`
+}
+
+// NewLock builds the lock for files generated from data.
+func NewLock(data Data, files map[string]File) *Lock {
+	lock := &Lock{
+		Version: Version,
+		Data:    data,
+		Files:   make([]LockedFile, 0, len(files)),
+	}
+
+	lock.Record(files)
+
+	return lock
+}
+
+// Record adds files to the lock, replacing the entries already recorded for
+// their paths.
+func (l *Lock) Record(files map[string]File) {
+	kept := l.Files[:0]
+	for _, f := range l.Files {
+		if _, ok := files[f.Path]; !ok {
+			kept = append(kept, f)
+		}
+	}
+	l.Files = kept
+
+	for _, f := range files {
+		l.Files = append(l.Files, LockedFile{
+			Path:      f.Path,
+			Generator: f.Generator,
+			Templates: f.Templates,
+			Layers:    f.Layers,
+			Hash:      Hash(f.Content),
+			Mode:      f.Mode,
+		})
+	}
+
+	sort.Slice(l.Files, func(i, j int) bool {
+		return l.Files[i].Path < l.Files[j].Path
+	})
+}
+
+// ReadLock loads the lock file of the project in dir.
+func ReadLock(dir string) (*Lock, error) {
+	content, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	lock := new(Lock)
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+
+	return lock, nil
+}
+
+// Marshal encodes the lock in its on-disk format.
+func (l *Lock) Marshal() ([]byte, error) {
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lock file: %w", err)
+	}
+
+	return append(content, '\n'), nil
+}
+
+// File returns the entry recorded for path, if any.
+func (l *Lock) File(path string) (LockedFile, bool) {
+	for _, f := range l.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+
+	return LockedFile{}, false
+}
+
+// Base returns the merge base of path in the project in dir, or nil when it
+// is missing or does not match the hash recorded in the lock.
+func (l *Lock) Base(dir, p string) []byte {
+	locked, ok := l.File(p)
+	if !ok {
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(BasePath(p))))
+	if err != nil || Hash(content) != locked.Hash {
+		return nil
+	}
+
+	return content
+}
+
+// BasePath returns the path the merge base of the file at p is kept at.
+func BasePath(p string) string {
+	return path.Join(BaseDir, p)
+}
+
+// BaseFiles returns the merge bases of files, to be written along with the
+// lock recording them.
+func BaseFiles(files map[string]File) map[string]File {
+	bases := make(map[string]File, len(files))
+
+	for _, f := range files {
+		p := BasePath(f.Path)
+		bases[p] = File{Path: p, Content: f.Content, Mode: DefaultFileMode}
+	}
+
+	return bases
+}
+
+// Hash returns the content hash stored in lock files.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
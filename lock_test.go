@@ -0,0 +1,161 @@
+package craft_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func lockFiles() map[string]craft.File {
+	return map[string]craft.File{
+		"go.mod": {
+			Path:      "go.mod",
+			Generator: "common",
+			Templates: []string{"common/go.mod.tmpl"},
+			Layers:    []string{"embedded"},
+			Content:   []byte("module example.com/example\n"),
+			Mode:      0o644,
+		},
+		"scripts/build": {
+			Path:      "scripts/build",
+			Generator: "script",
+			Templates: []string{"scripts/build.tmpl"},
+			Content:   []byte("#!/bin/sh\n"),
+			Mode:      0o755,
+		},
+	}
+}
+
+// writeLock writes the lock and the merge bases of files to dir.
+func writeLock(t *testing.T, dir string, lock *craft.Lock, files map[string]craft.File) {
+	t.Helper()
+
+	content, err := lock.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, dir, craft.LockFileName, content)
+
+	for path, f := range craft.BaseFiles(files) {
+		writeFile(t, dir, path, f.Content)
+	}
+}
+
+func TestLockRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := lockFiles()
+
+	lock := craft.NewLock(testData(), files)
+	lock.Generators = []string{"common", "script"}
+
+	writeLock(t, dir, lock, files)
+
+	got, err := craft.ReadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, lock) {
+		t.Errorf("got lock %+v, want %+v", got, lock)
+	}
+
+	want := []craft.LockedFile{
+		{
+			Path:      "go.mod",
+			Generator: "common",
+			Templates: []string{"common/go.mod.tmpl"},
+			Layers:    []string{"embedded"},
+			Hash:      craft.Hash([]byte("module example.com/example\n")),
+			Mode:      0o644,
+		},
+		{
+			Path:      "scripts/build",
+			Generator: "script",
+			Templates: []string{"scripts/build.tmpl"},
+			Hash:      craft.Hash([]byte("#!/bin/sh\n")),
+			Mode:      0o755,
+		},
+	}
+
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Errorf("got files %+v, want %+v", got.Files, want)
+	}
+
+	if base := got.Base(dir, "go.mod"); string(base) != "module example.com/example\n" {
+		t.Errorf("got base of go.mod %q", base)
+	}
+}
+
+func TestLockRecord(t *testing.T) {
+	files := lockFiles()
+	lock := craft.NewLock(testData(), files)
+
+	lock.Record(map[string]craft.File{
+		"go.mod":  {Path: "go.mod", Generator: "common", Content: []byte("module example.com/other\n")},
+		"LICENSE": {Path: "LICENSE", Generator: "license", Content: []byte("MIT\n")},
+	})
+
+	var paths []string
+	for _, f := range lock.Files {
+		paths = append(paths, f.Path)
+	}
+
+	if want := []string{"LICENSE", "go.mod", "scripts/build"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+
+	if f, _ := lock.File("go.mod"); f.Hash != craft.Hash([]byte("module example.com/other\n")) {
+		t.Errorf("go.mod was not replaced: %+v", f)
+	}
+}
+
+func TestLockBase(t *testing.T) {
+	dir := t.TempDir()
+	files := lockFiles()
+	lock := craft.NewLock(testData(), files)
+
+	writeLock(t, dir, lock, files)
+
+	// A base edited by hand no longer matches the lock.
+	writeFile(t, dir, craft.BasePath("scripts/build"), []byte("#!/bin/bash\n"))
+
+	for path, want := range map[string][]byte{
+		"go.mod":        []byte("module example.com/example\n"),
+		"scripts/build": nil,
+		"README.md":     nil,
+	} {
+		if got := lock.Base(dir, path); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got base %q, want %q", path, got, want)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, filepath.FromSlash(craft.BasePath("go.mod")))); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lock.Base(dir, "go.mod"); got != nil {
+		t.Errorf("got base %q of a removed file, want none", got)
+	}
+}
+
+func TestReadLockMissing(t *testing.T) {
+	_, err := craft.ReadLock(t.TempDir())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestReadLockInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, craft.LockFileName, []byte("{"))
+
+	if _, err := craft.ReadLock(dir); err == nil {
+		t.Error("got no error from an invalid lock file")
+	}
+}
@@ -0,0 +1,128 @@
+package craft
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memFS is a read-only fs.FS over files kept in memory by path. Directories
+// are implied by the paths of the files they hold.
+type memFS map[string]*memFile
+
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if f, ok := m[name]; ok {
+		info := &memInfo{name: path.Base(name), size: int64(len(f.data)), mode: f.mode, modTime: f.modTime}
+
+		return &memOpenFile{info: info, Reader: bytes.NewReader(f.data)}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	children := make(map[string]*memInfo)
+
+	for p, f := range m {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+
+		child, _, isDir := strings.Cut(rest, "/")
+		if isDir {
+			children[child] = &memInfo{name: child, mode: fs.ModeDir | 0555}
+		} else {
+			children[child] = &memInfo{name: child, size: int64(len(f.data)), mode: f.mode, modTime: f.modTime}
+		}
+	}
+
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, info := range children {
+		entries = append(entries, info)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return &memDir{info: &memInfo{name: path.Base(name), mode: fs.ModeDir | 0555}, entries: entries}, nil
+}
+
+// memInfo describes a file or directory of a memFS.
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *memInfo) Name() string               { return i.name }
+func (i *memInfo) Size() int64                { return i.size }
+func (i *memInfo) Mode() fs.FileMode          { return i.mode }
+func (i *memInfo) Type() fs.FileMode          { return i.mode.Type() }
+func (i *memInfo) ModTime() time.Time         { return i.modTime }
+func (i *memInfo) IsDir() bool                { return i.mode.IsDir() }
+func (i *memInfo) Sys() interface{}           { return nil }
+func (i *memInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+type memOpenFile struct {
+	*bytes.Reader
+	info *memInfo
+}
+
+func (f *memOpenFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memOpenFile) Close() error               { return nil }
+
+type memDir struct {
+	info    *memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(rest) {
+		n = len(rest)
+	}
+
+	d.offset += n
+
+	return rest[:n], nil
+}
@@ -0,0 +1,109 @@
+package craft
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written by Merge when both sides changed the same lines.
+const (
+	conflictStart  = "<<<<<<< current\n"
+	conflictBase   = "||||||| base\n"
+	conflictMiddle = "=======\n"
+	conflictEnd    = ">>>>>>> generated\n"
+)
+
+// Merge performs a line based three-way merge. base is the content craft
+// generated previously, current is the file on disk and generated is the
+// freshly rendered content. Hunks changed on both sides are wrapped in
+// conflict markers, in which case the returned bool is true.
+func Merge(base, current, generated []byte) ([]byte, bool) {
+	b, c, g := splitLines(base), splitLines(current), splitLines(generated)
+
+	mc, mg := matchLines(b, c), matchLines(b, g)
+
+	out := new(strings.Builder)
+	conflict := false
+
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(c) || k < len(g) {
+		if i < len(b) && mc[i] == j && mg[i] == k {
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Find the next base line kept by both sides; everything before it
+		// is an unstable chunk.
+		next := i
+		for next < len(b) && (mc[next] < 0 || mg[next] < 0) {
+			next++
+		}
+
+		endC, endG := len(c), len(g)
+		if next < len(b) {
+			endC, endG = mc[next], mg[next]
+		}
+
+		chunkB, chunkC, chunkG := b[i:next], c[j:endC], g[k:endG]
+
+		switch {
+		case slices.Equal(chunkC, chunkB):
+			writeLines(out, chunkG)
+		case slices.Equal(chunkG, chunkB), slices.Equal(chunkC, chunkG):
+			writeLines(out, chunkC)
+		default:
+			conflict = true
+			out.WriteString(conflictStart)
+			writeConflictLines(out, chunkC)
+			out.WriteString(conflictBase)
+			writeConflictLines(out, chunkB)
+			out.WriteString(conflictMiddle)
+			writeConflictLines(out, chunkG)
+			out.WriteString(conflictEnd)
+		}
+
+		i, j, k = next, endC, endG
+	}
+
+	return []byte(out.String()), conflict
+}
+
+// matchLines maps every line of a to the index of the line of b it is
+// paired with in their longest common subsequence, or -1.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+
+	i, j := 0, 0
+	for _, l := range diffLines(a, b) {
+		switch l.Op {
+		case opEqual:
+			match[i] = j
+			i++
+			j++
+		case opDelete:
+			match[i] = -1
+			i++
+		case opInsert:
+			j++
+		}
+	}
+
+	return match
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// writeConflictLines writes one side of a conflict, making sure it ends with
+// a newline so that the next marker starts on its own line.
+func writeConflictLines(out *strings.Builder, lines []string) {
+	writeLines(out, lines)
+
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,114 @@
+package craft_test
+
+import (
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		current   string
+		generated string
+		want      string
+		conflict  bool
+	}{
+		{
+			name:      "unchanged",
+			base:      "a\nb\nc\n",
+			current:   "a\nb\nc\n",
+			generated: "a\nb\nc\n",
+			want:      "a\nb\nc\n",
+		},
+		{
+			name:      "generated changed",
+			base:      "a\nb\nc\n",
+			current:   "a\nb\nc\n",
+			generated: "a\nB\nc\n",
+			want:      "a\nB\nc\n",
+		},
+		{
+			name:      "current changed",
+			base:      "a\nb\nc\n",
+			current:   "a\nB\nc\n",
+			generated: "a\nb\nc\n",
+			want:      "a\nB\nc\n",
+		},
+		{
+			name:      "clean merge",
+			base:      "a\nb\nc\nd\n",
+			current:   "A\nb\nc\nd\n",
+			generated: "a\nb\nc\nD\n",
+			want:      "A\nb\nc\nD\n",
+		},
+		{
+			name:      "same change on both sides",
+			base:      "a\nb\nc\n",
+			current:   "a\nB\nc\n",
+			generated: "a\nB\nc\n",
+			want:      "a\nB\nc\n",
+		},
+		{
+			name:      "insertion at EOF",
+			base:      "a\nb\n",
+			current:   "a\nb\nc\n",
+			generated: "A\nb\n",
+			want:      "A\nb\nc\n",
+		},
+		{
+			name:      "insertions at EOF on both sides",
+			base:      "a\n",
+			current:   "a\nc\n",
+			generated: "a\ng\n",
+			want:      "a\n<<<<<<< current\nc\n||||||| base\n=======\ng\n>>>>>>> generated\n",
+			conflict:  true,
+		},
+		{
+			name:      "deletion",
+			base:      "a\nb\nc\nd\n",
+			current:   "a\nc\nd\n",
+			generated: "a\nb\nc\nD\n",
+			want:      "a\nc\nD\n",
+		},
+		{
+			name:      "conflict",
+			base:      "a\nb\nc\n",
+			current:   "a\nmine\nc\n",
+			generated: "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< current\nmine\n||||||| base\nb\n=======\ntheirs\n>>>>>>> generated\nc\n",
+			conflict:  true,
+		},
+		{
+			name:      "conflict without trailing newline",
+			base:      "a\nb",
+			current:   "a\nmine",
+			generated: "a\ntheirs",
+			want:      "a\n<<<<<<< current\nmine\n||||||| base\nb\n=======\ntheirs\n>>>>>>> generated\n",
+			conflict:  true,
+		},
+		{
+			name:      "missing base",
+			base:      "",
+			current:   "a\n",
+			generated: "b\n",
+			want:      "<<<<<<< current\na\n||||||| base\n=======\nb\n>>>>>>> generated\n",
+			conflict:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := craft.Merge([]byte(tt.base), []byte(tt.current), []byte(tt.generated))
+
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+
+			if conflict != tt.conflict {
+				t.Errorf("got conflict %v, want %v", conflict, tt.conflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package craft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ChangeKind describes what writing a generated file would do to the
+// target directory.
+type ChangeKind string
+
+const (
+	ChangeNew       ChangeKind = "new"
+	ChangeModified  ChangeKind = "changed"
+	ChangeUnchanged ChangeKind = "unchanged"
+	// ChangeSkipped is a create-only file that already exists.
+	ChangeSkipped ChangeKind = "skipped"
+)
+
+// Change is a single entry of a Plan.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Old  []byte
+	New  []byte
+}
+
+// Diff renders the change as a unified diff against the file on disk.
+func (c Change) Diff() string {
+	oldName := filepath.ToSlash(filepath.Join("a", c.Path))
+	if c.Kind == ChangeNew {
+		oldName = "/dev/null"
+	}
+
+	return UnifiedDiff(oldName, filepath.ToSlash(filepath.Join("b", c.Path)), c.Old, c.New)
+}
+
+// Plan compares the generated files with the contents of dir and reports,
+// sorted by path, which files would be created, changed or left untouched.
+func Plan(dir string, files map[string]File) ([]Change, error) {
+	changes := make([]Change, 0, len(files))
+
+	for path, f := range files {
+		change := Change{Path: path, Kind: ChangeNew, New: f.Content}
+
+		old, err := os.ReadFile(filepath.Join(dir, path))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		case f.CreateOnly:
+			change.Kind = ChangeSkipped
+			change.Old = old
+			change.New = old
+		case bytes.Equal(old, f.Content):
+			change.Kind = ChangeUnchanged
+			change.Old = old
+		default:
+			change.Kind = ChangeModified
+			change.Old = old
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
@@ -0,0 +1,279 @@
+package craft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginPrefix is the prefix of the executables discovered as generators.
+const PluginPrefix = "craft-gen-"
+
+// PluginTimeout bounds every call to a plugin, so that a plugin that hangs
+// does not hang craft.
+var PluginTimeout = 30 * time.Second
+
+// Plugins talk to craft over a JSON protocol: craft runs the executable,
+// writes a PluginRequest to its standard input and reads a PluginResponse
+// from its standard output. A non-empty Error, or a non-zero exit status,
+// fails the request.
+//
+// The describe command asks for the metadata of the generator, validate
+// checks Data and generate returns the files, either as verbatim content in
+// Files or as templates to render in Render.
+type PluginRequest struct {
+	Command string `json:"command"`
+	Data    *Data  `json:"data,omitempty"`
+}
+
+// PluginResponse is the answer of a plugin to a PluginRequest.
+type PluginResponse struct {
+	Error string `json:"error,omitempty"`
+
+	// Set by describe.
+	Name         string   `json:"name,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Features     []string `json:"features,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+
+	// Set by generate.
+	Files  map[string]PluginFile   `json:"files,omitempty"`
+	Render map[string]PluginRender `json:"render,omitempty"`
+}
+
+// PluginFile is a file returned as is by a plugin.
+type PluginFile struct {
+	Content  string      `json:"content"`
+	Mode     fs.FileMode `json:"mode,omitempty"`
+	Features []string    `json:"features,omitempty"`
+}
+
+// PluginRender is a file a plugin asks craft to render from its templates.
+// The templates receive Data, a JSON object, or the project data when Data
+// is empty.
+type PluginRender struct {
+	Templates []string        `json:"templates"`
+	Execute   string          `json:"execute,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Mode      fs.FileMode     `json:"mode,omitempty"`
+	Features  []string        `json:"features,omitempty"`
+}
+
+// PluginData is the data a plugin renders a file with.
+type PluginData map[string]any
+
+func (PluginData) templateData() {}
+
+// Plugin is a Generator backed by an external executable.
+type Plugin struct {
+	Path string
+	info PluginResponse
+}
+
+// LoadPlugin asks the executable at path to describe itself.
+func LoadPlugin(ctx context.Context, path string) (*Plugin, error) {
+	p := &Plugin{Path: path}
+
+	info, err := p.call(ctx, PluginRequest{Command: "describe"})
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Name == "" {
+		info.Name = strings.TrimPrefix(filepath.Base(path), PluginPrefix)
+	}
+	p.info = info
+
+	return p, nil
+}
+
+// DiscoverPlugins loads the craft-gen-* executables found in dirs and then
+// in the directories of $PATH. When several share a name, the first one
+// found wins. Plugins that fail to load are left out and returned along
+// with the reason, so that a single broken plugin does not break craft.
+func DiscoverPlugins(ctx context.Context, dirs ...string) ([]*Plugin, []error) {
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+
+	seen := make(map[string]bool)
+
+	var (
+		plugins []*Plugin
+		errs    []error
+	)
+
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, PluginPrefix+"*"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid plugins directory %s: %w", dir, err))
+			continue
+		}
+
+		sort.Strings(matches)
+
+		for _, path := range matches {
+			name := filepath.Base(path)
+			if seen[name] || !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+
+			p, err := LoadPlugin(ctx, path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			plugins = append(plugins, p)
+		}
+	}
+
+	return plugins, errs
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
+
+func (p *Plugin) Name() string           { return p.info.Name }
+func (p *Plugin) Description() string    { return p.info.Description }
+func (p *Plugin) Features() []string     { return p.info.Features }
+func (p *Plugin) Dependencies() []string { return p.info.Dependencies }
+
+func (p *Plugin) Validate(data Data) error {
+	return p.ValidateContext(context.Background(), data)
+}
+
+func (p *Plugin) Generate(data Data) (map[string]RenderOptions, error) {
+	return p.GenerateContext(context.Background(), data)
+}
+
+// ValidateContext is Validate, killing the plugin when ctx is done.
+func (p *Plugin) ValidateContext(ctx context.Context, data Data) error {
+	_, err := p.call(ctx, PluginRequest{Command: "validate", Data: &data})
+
+	return err
+}
+
+// GenerateContext is Generate, killing the plugin when ctx is done. Paths
+// must be relative to the project, stay inside it and leave alone the lock
+// file and the .craft directory.
+func (p *Plugin) GenerateContext(ctx context.Context, data Data) (map[string]RenderOptions, error) {
+	resp, err := p.call(ctx, PluginRequest{Command: "generate", Data: &data})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, paths := range [][]string{mapKeys(resp.Files), mapKeys(resp.Render)} {
+		for _, path := range paths {
+			if !fs.ValidPath(path) || path == "." {
+				return nil, fmt.Errorf("plugin %s: invalid path %q, expected a path inside the project", p.Name(), path)
+			}
+
+			if isReservedPath(path) {
+				return nil, fmt.Errorf("plugin %s: invalid path %q, reserved for the files of craft", p.Name(), path)
+			}
+		}
+	}
+
+	out := make(map[string]RenderOptions, len(resp.Files)+len(resp.Render))
+
+	for path, f := range resp.Files {
+		out[path] = RenderOptions{
+			Content:  []byte(f.Content),
+			Mode:     f.Mode,
+			Features: f.Features,
+		}
+	}
+
+	for path, r := range resp.Render {
+		var tmplData TemplateData = data
+		if len(r.Data) > 0 {
+			var values PluginData
+			if err := json.Unmarshal(r.Data, &values); err != nil {
+				return nil, fmt.Errorf("plugin %s: invalid data for %s: %w", p.Name(), path, err)
+			}
+
+			tmplData = values
+		}
+
+		out[path] = RenderOptions{
+			Templates: r.Templates,
+			Data:      tmplData,
+			Execute:   r.Execute,
+			Mode:      r.Mode,
+			Features:  r.Features,
+		}
+	}
+
+	return out, nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (p *Plugin) call(ctx context.Context, req PluginRequest) (PluginResponse, error) {
+	var resp PluginResponse
+
+	in, err := json.Marshal(req)
+	if err != nil {
+		return resp, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		return resp, fmt.Errorf("plugin %s: %s failed: %w", p.Path, req.Command, err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return resp, fmt.Errorf("plugin %s: invalid %s response: %w", p.Path, req.Command, err)
+	}
+
+	if resp.Error != "" {
+		return resp, fmt.Errorf("plugin %s: %s", p.Path, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// isReservedPath reports whether path is the lock file or inside the
+// directory holding the merge bases, which only craft writes.
+func isReservedPath(path string) bool {
+	dir, _, _ := strings.Cut(BaseDir, "/")
+
+	return path == LockFileName || path == dir || strings.HasPrefix(path, dir+"/")
+}
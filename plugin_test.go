@@ -0,0 +1,228 @@
+package craft_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// TestMain turns the test binary into a fake plugin when it runs under a
+// craft-gen-* name, see fakePlugin.
+func TestMain(m *testing.M) {
+	if name := filepath.Base(os.Args[0]); strings.HasPrefix(name, craft.PluginPrefix) {
+		os.Exit(fakePlugin(strings.TrimPrefix(name, craft.PluginPrefix)))
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakePluginPath is the environment variable holding the path of the file
+// generated by the escape plugin.
+const fakePluginPath = "CRAFT_FAKE_PLUGIN_PATH"
+
+// fakePlugin answers a plugin request the way the plugin called name does:
+// good behaves, rendering a file with the project data and another with its
+// own, broken fails, hang never answers and escape generates the file at
+// $CRAFT_FAKE_PLUGIN_PATH.
+func fakePlugin(name string) int {
+	switch name {
+	case "broken":
+		fmt.Fprintln(os.Stderr, "boom")
+		return 1
+	case "hang":
+		time.Sleep(time.Hour)
+		return 1
+	}
+
+	var req craft.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var resp craft.PluginResponse
+
+	switch req.Command {
+	case "describe":
+		resp.Name = name
+		resp.Description = "Fake plugin"
+	case "generate":
+		path := name + ".txt"
+		if name == "escape" {
+			path = os.Getenv(fakePluginPath)
+		}
+
+		resp.Files = map[string]craft.PluginFile{
+			path: {Content: "Hello, " + req.Data.ProjectName + "\n"},
+		}
+
+		if name == "good" {
+			resp.Render = map[string]craft.PluginRender{
+				"project.txt": {Templates: []string{"good/project.tmpl"}},
+				"values.txt":  {Templates: []string{"good/values.tmpl"}, Data: json.RawMessage(`{"greeting": "Hi"}`)},
+			}
+		}
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// installPlugins links the test binary into a new directory under the
+// craft-gen-* names given and returns the directory.
+func installPlugins(t *testing.T, names ...string) string {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	for _, name := range names {
+		if err := os.Symlink(exe, filepath.Join(dir, craft.PluginPrefix+name)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	// Keep the plugins installed on the machine out of the tests.
+	t.Setenv("PATH", "")
+
+	return dir
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := installPlugins(t, "good", "broken")
+
+	plugins, errs := craft.DiscoverPlugins(context.Background(), dir)
+
+	if len(plugins) != 1 || plugins[0].Name() != "good" {
+		t.Errorf("got plugins %v, want only good", plugins)
+	}
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "boom") {
+		t.Errorf("got errors %v, want the one of broken", errs)
+	}
+}
+
+func TestPluginGenerate(t *testing.T) {
+	dir := installPlugins(t, "good")
+
+	p, err := craft.LoadPlugin(context.Background(), filepath.Join(dir, craft.PluginPrefix+"good"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := craft.NewRegistry(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := craft.Manager{
+		Registry: registry,
+		Options: craft.Options{
+			Templates: fstest.MapFS{
+				"good/project.tmpl": {Data: []byte("Project {{.ProjectName}}\n")},
+				"good/values.tmpl":  {Data: []byte("{{.greeting}}\n")},
+			},
+		},
+	}
+
+	data := testData()
+	data.Framework = "cobra"
+
+	files, err := manager.Generate(context.Background(), data, "good")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"good.txt":    "Hello, example\n",
+		"project.txt": "Project example\n",
+		"values.txt":  "Hi\n",
+	} {
+		if got := string(files[path]); got != want {
+			t.Errorf("got %s %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestPluginInvalidPath(t *testing.T) {
+	dir := installPlugins(t, "escape")
+
+	p, err := craft.LoadPlugin(context.Background(), filepath.Join(dir, craft.PluginPrefix+"escape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"../escape.txt", "a/../../escape.txt", "/tmp/escape.txt", ".", ""} {
+		t.Run(path, func(t *testing.T) {
+			t.Setenv(fakePluginPath, path)
+
+			if _, err := p.Generate(testData()); err == nil || !strings.Contains(err.Error(), "invalid path") {
+				t.Errorf("got error %v, want invalid path", err)
+			}
+		})
+	}
+}
+
+func TestPluginReservedPath(t *testing.T) {
+	dir := installPlugins(t, "escape")
+
+	p, err := craft.LoadPlugin(context.Background(), filepath.Join(dir, craft.PluginPrefix+"escape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{craft.LockFileName, ".craft", ".craft/base/go.mod", ".craft/other"} {
+		t.Run(path, func(t *testing.T) {
+			t.Setenv(fakePluginPath, path)
+
+			if _, err := p.Generate(testData()); err == nil || !strings.Contains(err.Error(), "reserved for the files of craft") {
+				t.Errorf("got error %v, want a reserved path", err)
+			}
+		})
+	}
+
+	// Only the exact names are reserved.
+	for _, path := range []string{".craft.lock.bak", ".crafty/file", "docs/.craft/file"} {
+		t.Run(path, func(t *testing.T) {
+			t.Setenv(fakePluginPath, path)
+
+			if _, err := p.Generate(testData()); err != nil {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	dir := installPlugins(t, "hang")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := craft.LoadPlugin(ctx, filepath.Join(dir, craft.PluginPrefix+"hang")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("plugin killed after %s", elapsed)
+	}
+}
@@ -0,0 +1,294 @@
+package craft
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RegisterCommand registers cmd in its parent command, in the commands
+// package of the binary of the project in dir, and returns the edited file.
+// The parent is looked up by the name of its function wherever it lives in
+// the package. Cobra commands are added to its AddCommand call and urfave
+// ones to its Commands or Subcommands list, which are created when
+// missing.
+func RegisterCommand(dir string, data Data, cmd Command) (File, error) {
+	pkg := CommandsDir(data, cmd.Binary)
+
+	entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(pkg)))
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read commands package: %w", err)
+	}
+
+	fset := token.NewFileSet()
+
+	var (
+		parent *ast.FuncDecl
+		file   string
+		src    []byte
+		mode   os.FileMode
+	)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		p := path.Join(pkg, name)
+
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			return File{}, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+
+		f, err := parser.ParseFile(fset, p, content, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return File{}, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil {
+				continue
+			}
+
+			switch fn.Name.Name {
+			case cmd.FuncName():
+				return File{}, fmt.Errorf("%s is already declared in %s", cmd.FuncName(), p)
+			case cmd.ParentFuncName():
+				info, err := entry.Info()
+				if err != nil {
+					return File{}, err
+				}
+
+				parent, file, src, mode = fn, p, content, info.Mode().Perm()
+			}
+		}
+	}
+
+	if parent == nil {
+		return File{}, fmt.Errorf("parent command %s not found in %s", cmd.ParentFuncName(), pkg)
+	}
+
+	args, err := callArgs(parent)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to register %s in %s: %w", cmd.FuncName(), file, err)
+	}
+
+	call := fmt.Sprintf("%s(%s)", cmd.FuncName(), strings.Join(args, ", "))
+
+	var ins insertion
+
+	switch data.Framework {
+	case "cobra":
+		ins, err = cobraRegistration(fset, parent, call)
+	case "urfave":
+		key := "Subcommands"
+		if cmd.Parent == "" {
+			key = "Commands"
+		}
+
+		ins, err = urfaveRegistration(fset, parent, key, call)
+	default:
+		err = validateFramework(data)
+	}
+
+	if err != nil {
+		return File{}, err
+	}
+
+	offset := fset.Position(ins.pos).Offset
+
+	edited := make([]byte, 0, len(src)+len(ins.text))
+	edited = append(edited, src[:offset]...)
+	edited = append(edited, ins.text...)
+	edited = append(edited, src[offset:]...)
+
+	content, err := format.Source(edited)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to register %s in %s: %w", cmd.FuncName(), file, err)
+	}
+
+	return File{Path: file, Content: content, Mode: mode}, nil
+}
+
+// insertion is text to insert into a source file at pos.
+type insertion struct {
+	pos  token.Pos
+	text string
+}
+
+// cobraRegistration adds call to the last AddCommand call of fn, or adds
+// such a call before fn returns the command.
+func cobraRegistration(fset *token.FileSet, fn *ast.FuncDecl, call string) (insertion, error) {
+	var add *ast.CallExpr
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := c.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "AddCommand" {
+				add = c
+			}
+		}
+
+		return true
+	})
+
+	if add != nil {
+		return appendElement(fset, add.Args, add.Rparen, call), nil
+	}
+
+	if ret := lastReturn(fn); ret != nil && len(ret.Results) == 1 {
+		if id, ok := ret.Results[0].(*ast.Ident); ok {
+			return insertion{pos: ret.Pos(), text: fmt.Sprintf("%s.AddCommand(%s)\n\n", id.Name, call)}, nil
+		}
+	}
+
+	return insertion{}, fmt.Errorf("found no AddCommand call in %s, nor a command variable it returns", fn.Name.Name)
+}
+
+// urfaveRegistration adds call to the key list of fn, Commands or
+// Subcommands, or adds the list to the literal fn returns.
+func urfaveRegistration(fset *token.FileSet, fn *ast.FuncDecl, key, call string) (insertion, error) {
+	var list *ast.CompositeLit
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if kv, ok := n.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && id.Name == key {
+				if lit, ok := kv.Value.(*ast.CompositeLit); ok {
+					list = lit
+				}
+			}
+		}
+
+		return true
+	})
+
+	if list != nil {
+		return appendElement(fset, list.Elts, list.Rbrace, call), nil
+	}
+
+	if ret := lastReturn(fn); ret != nil && len(ret.Results) == 1 {
+		result := ret.Results[0]
+		if u, ok := result.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			result = u.X
+		}
+
+		if lit, ok := result.(*ast.CompositeLit); ok {
+			if typ, ok := lit.Type.(*ast.SelectorExpr); ok {
+				if pkg, ok := typ.X.(*ast.Ident); ok {
+					return appendElement(fset, lit.Elts, lit.Rbrace, fmt.Sprintf("%s: []*%s.Command{%s}", key, pkg.Name, call)), nil
+				}
+			}
+		}
+	}
+
+	return insertion{}, fmt.Errorf("found no %s list in %s, nor a command literal it returns", key, fn.Name.Name)
+}
+
+// appendElement appends text to a list of elements closed at end, on a
+// line of its own unless the list fits on one line.
+func appendElement(fset *token.FileSet, elts []ast.Expr, end token.Pos, text string) insertion {
+	if len(elts) == 0 {
+		return insertion{pos: end, text: "\n" + text + ",\n"}
+	}
+
+	last := elts[len(elts)-1].End()
+	if fset.Position(last).Line == fset.Position(end).Line {
+		return insertion{pos: last, text: ", " + text}
+	}
+
+	return insertion{pos: last, text: ",\n" + text}
+}
+
+func lastReturn(fn *ast.FuncDecl) *ast.ReturnStmt {
+	for i := len(fn.Body.List) - 1; i >= 0; i-- {
+		if ret, ok := fn.Body.List[i].(*ast.ReturnStmt); ok {
+			return ret
+		}
+	}
+
+	return nil
+}
+
+// callArgs returns the arguments passing the context of fn on to the
+// commands it registers: its two parameters, the context and the
+// application context.
+func callArgs(fn *ast.FuncDecl) ([]string, error) {
+	var names []string
+
+	for _, field := range fn.Type.Params.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s has unnamed parameters, expected a context and an application context", fn.Name.Name)
+		}
+
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				return nil, fmt.Errorf("%s ignores its parameters, expected a context and an application context", fn.Name.Name)
+			}
+
+			names = append(names, name.Name)
+		}
+	}
+
+	if len(names) != 2 {
+		return nil, fmt.Errorf("%s takes %d parameters, expected a context and an application context", fn.Name.Name, len(names))
+	}
+
+	return names, nil
+}
+
+// ListCommand adds cmd to the Commands section of a README generated by
+// craft, after the other commands of its binary. It reports false when the
+// README has no such section.
+func ListCommand(readme []byte, cmd Command) ([]byte, bool) {
+	lines := strings.SplitAfter(string(readme), "\n")
+
+	section := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "### Commands" {
+			section = i
+			break
+		}
+	}
+
+	if section < 0 {
+		return readme, false
+	}
+
+	// The list may be separated from the heading by blank lines. A new
+	// list goes right under the heading.
+	start := section + 1
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+
+	if start == len(lines) || !strings.HasPrefix(lines[start], "- ") {
+		start = section + 1
+	}
+
+	at, last := -1, start
+	for i := start; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+		if strings.HasPrefix(lines[i], "- `"+cmd.Binary+" ") {
+			at = i + 1
+		}
+
+		last = i + 1
+	}
+
+	if at < 0 {
+		at = last
+	}
+
+	entry := fmt.Sprintf("- `%s %s`\n", cmd.Binary, cmd.Path())
+
+	out := strings.Join(lines[:at], "") + entry + strings.Join(lines[at:], "")
+
+	return []byte(out), true
+}
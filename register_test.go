@@ -0,0 +1,512 @@
+package craft_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+const cobraRoot = `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cobra.Command {
+	cmd := &cobra.Command{Use: "example"}
+
+	cmd.AddCommand(
+		CmdVersion(ctx, appCtx),
+	)
+
+	return cmd
+}
+`
+
+const urfaveRoot = `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cli.App {
+	return &cli.App{
+		Name: "example",
+		Commands: []*cli.Command{
+			CmdVersion(ctx, appCtx),
+		},
+	}
+}
+`
+
+const urfaveMigrate = `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdMigrate(ctx context.Context, appCtx *Context) *cli.Command {
+	return &cli.Command{
+		Name: "migrate",
+	}
+}
+`
+
+func TestRegisterCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		framework string
+		binaries  []string
+		files     map[string]string
+		cmd       craft.Command
+		wantPath  string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "cobra AddCommand",
+			framework: "cobra",
+			files:     map[string]string{"root.go": cobraRoot},
+			cmd:       craft.Command{Binary: "example", Name: "status"},
+			wantPath:  "internal/commands/root.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cobra.Command {
+	cmd := &cobra.Command{Use: "example"}
+
+	cmd.AddCommand(
+		CmdVersion(ctx, appCtx),
+		CmdStatus(ctx, appCtx),
+	)
+
+	return cmd
+}
+`,
+		},
+		{
+			name:      "cobra without AddCommand",
+			framework: "cobra",
+			files: map[string]string{"root.go": `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdRoot(c context.Context, app *Context) *cobra.Command {
+	root := &cobra.Command{Use: "example"}
+
+	return root
+}
+`},
+			cmd:      craft.Command{Binary: "example", Name: "status"},
+			wantPath: "internal/commands/root.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdRoot(c context.Context, app *Context) *cobra.Command {
+	root := &cobra.Command{Use: "example"}
+
+	root.AddCommand(CmdStatus(c, app))
+
+	return root
+}
+`,
+		},
+		{
+			name:      "cobra nested parent",
+			framework: "cobra",
+			binaries:  []string{"examplectl", "exampled"},
+			files: map[string]string{"root.go": cobraRoot, "db_migrate.go": `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdDbMigrate(ctx context.Context, appCtx *Context) *cobra.Command {
+	cmd := &cobra.Command{Use: "migrate"}
+
+	return cmd
+}
+`},
+			cmd:      craft.Command{Binary: "examplectl", Parent: "db migrate", Name: "up"},
+			wantPath: "internal/commands/examplectl/db_migrate.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdDbMigrate(ctx context.Context, appCtx *Context) *cobra.Command {
+	cmd := &cobra.Command{Use: "migrate"}
+
+	cmd.AddCommand(CmdDbMigrateUp(ctx, appCtx))
+
+	return cmd
+}
+`,
+		},
+		{
+			name:      "urfave Commands",
+			framework: "urfave",
+			files:     map[string]string{"root.go": urfaveRoot, "migrate.go": urfaveMigrate},
+			cmd:       craft.Command{Binary: "example", Name: "status"},
+			wantPath:  "internal/commands/root.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cli.App {
+	return &cli.App{
+		Name: "example",
+		Commands: []*cli.Command{
+			CmdVersion(ctx, appCtx),
+			CmdStatus(ctx, appCtx),
+		},
+	}
+}
+`,
+		},
+		{
+			name:      "urfave missing Commands",
+			framework: "urfave",
+			files: map[string]string{"root.go": `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cli.App {
+	return &cli.App{Name: "example"}
+}
+`},
+			cmd:      craft.Command{Binary: "example", Name: "status"},
+			wantPath: "internal/commands/root.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdRoot(ctx context.Context, appCtx *Context) *cli.App {
+	return &cli.App{Name: "example", Commands: []*cli.Command{CmdStatus(ctx, appCtx)}}
+}
+`,
+		},
+		{
+			name:      "urfave missing Subcommands",
+			framework: "urfave",
+			files:     map[string]string{"root.go": urfaveRoot, "migrate.go": urfaveMigrate},
+			cmd:       craft.Command{Binary: "example", Parent: "migrate", Name: "up"},
+			wantPath:  "internal/commands/migrate.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdMigrate(ctx context.Context, appCtx *Context) *cli.Command {
+	return &cli.Command{
+		Name:        "migrate",
+		Subcommands: []*cli.Command{CmdMigrateUp(ctx, appCtx)},
+	}
+}
+`,
+		},
+		{
+			name:      "urfave Subcommands",
+			framework: "urfave",
+			files: map[string]string{"root.go": urfaveRoot, "migrate.go": `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdMigrate(ctx context.Context, appCtx *Context) *cli.Command {
+	return &cli.Command{
+		Name: "migrate",
+		Subcommands: []*cli.Command{
+			CmdMigrateDown(ctx, appCtx),
+		},
+	}
+}
+`},
+			cmd:      craft.Command{Binary: "example", Parent: "migrate", Name: "up"},
+			wantPath: "internal/commands/migrate.go",
+			want: `package commands
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CmdMigrate(ctx context.Context, appCtx *Context) *cli.Command {
+	return &cli.Command{
+		Name: "migrate",
+		Subcommands: []*cli.Command{
+			CmdMigrateDown(ctx, appCtx),
+			CmdMigrateUp(ctx, appCtx),
+		},
+	}
+}
+`,
+		},
+		{
+			name:      "already declared",
+			framework: "cobra",
+			files: map[string]string{"root.go": cobraRoot, "status.go": `package commands
+
+func CmdStatus() {}
+`},
+			cmd:     craft.Command{Binary: "example", Name: "status"},
+			wantErr: "CmdStatus is already declared in internal/commands/status.go",
+		},
+		{
+			name:      "parent not found",
+			framework: "cobra",
+			files:     map[string]string{"root.go": cobraRoot},
+			cmd:       craft.Command{Binary: "example", Parent: "migrate", Name: "up"},
+			wantErr:   "parent command CmdMigrate not found in internal/commands",
+		},
+		{
+			name:      "parent arity",
+			framework: "cobra",
+			files: map[string]string{"root.go": `package commands
+
+import "github.com/spf13/cobra"
+
+func CmdRoot(appCtx *Context) *cobra.Command {
+	cmd := &cobra.Command{Use: "example"}
+
+	return cmd
+}
+`},
+			cmd:     craft.Command{Binary: "example", Name: "status"},
+			wantErr: "CmdRoot takes 1 parameters, expected a context and an application context",
+		},
+		{
+			name:      "unnamed parameters",
+			framework: "cobra",
+			files: map[string]string{"root.go": `package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func CmdRoot(context.Context, *Context) *cobra.Command {
+	return &cobra.Command{Use: "example"}
+}
+`},
+			cmd:     craft.Command{Binary: "example", Name: "status"},
+			wantErr: "CmdRoot has unnamed parameters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := craft.Data{Binaries: tt.binaries}
+			data.Framework = tt.framework
+
+			if data.Binaries == nil {
+				data.Binaries = []string{tt.cmd.Binary}
+			}
+
+			dir := t.TempDir()
+
+			for name, src := range tt.files {
+				writeFile(t, dir, craft.CommandsDir(data, tt.cmd.Binary)+"/"+name, []byte(src))
+			}
+
+			f, err := craft.RegisterCommand(dir, data, tt.cmd)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if f.Path != tt.wantPath {
+				t.Errorf("got path %s, want %s", f.Path, tt.wantPath)
+			}
+
+			if string(f.Content) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", f.Content, tt.want)
+			}
+		})
+	}
+}
+
+// TestRegisterCommandGenerated registers commands in the projects craft
+// generates.
+func TestRegisterCommandGenerated(t *testing.T) {
+	for _, framework := range craft.CLIFrameworks {
+		t.Run(framework, func(t *testing.T) {
+			data := testData()
+			data.Framework = framework
+			data.Binaries = []string{"examplectl", "exampled"}
+			data.Commands = []craft.Command{{Binary: "examplectl", Name: "migrate"}}
+
+			manager := craft.Manager{
+				Registry: craft.DefaultRegistry(),
+				Options: craft.Options{
+					Templates: os.DirFS(filepath.Join("cmd", "craft", "templates")),
+				},
+			}
+
+			files, err := manager.Render(context.Background(), data, "commands")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+			for path, f := range files {
+				writeFile(t, dir, path, f.Content)
+			}
+
+			for _, cmd := range []craft.Command{
+				{Binary: "examplectl", Name: "status"},
+				{Binary: "examplectl", Parent: "migrate", Name: "up"},
+				{Binary: "exampled", Parent: "server", Name: "reload"},
+			} {
+				f, err := craft.RegisterCommand(dir, data, cmd)
+				if err != nil {
+					t.Fatalf("%s %s: %v", cmd.Binary, cmd.Path(), err)
+				}
+
+				if !strings.Contains(string(f.Content), cmd.FuncName()+"(ctx, appCtx)") {
+					t.Errorf("%s does not call %s:\n%s", f.Path, cmd.FuncName(), f.Content)
+				}
+
+				if _, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, 0); err != nil {
+					t.Error(err)
+				}
+			}
+
+			if _, err := craft.RegisterCommand(dir, data, craft.Command{Binary: "examplectl", Name: "migrate"}); err == nil || !strings.Contains(err.Error(), "already declared") {
+				t.Errorf("got error %v, want already declared", err)
+			}
+		})
+	}
+}
+
+const readme = `# example
+
+### Commands
+
+- ` + "`examplectl migrate`" + `
+- ` + "`examplectl migrate up`" + `
+- ` + "`exampled server`" + `
+
+## License
+`
+
+func TestListCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		cmd    craft.Command
+		want   string
+		listed bool
+	}{
+		{
+			name:   "after the commands of the binary",
+			readme: readme,
+			cmd:    craft.Command{Binary: "examplectl", Name: "status"},
+			want: "# example\n\n### Commands\n\n" +
+				"- `examplectl migrate`\n- `examplectl migrate up`\n- `examplectl status`\n- `exampled server`\n\n## License\n",
+			listed: true,
+		},
+		{
+			name:   "nested",
+			readme: readme,
+			cmd:    craft.Command{Binary: "exampled", Parent: "server", Name: "reload"},
+			want: "# example\n\n### Commands\n\n" +
+				"- `examplectl migrate`\n- `examplectl migrate up`\n- `exampled server`\n- `exampled server reload`\n\n## License\n",
+			listed: true,
+		},
+		{
+			name:   "first command of the binary",
+			readme: readme,
+			cmd:    craft.Command{Binary: "examplectl2", Name: "status"},
+			want: "# example\n\n### Commands\n\n" +
+				"- `examplectl migrate`\n- `examplectl migrate up`\n- `exampled server`\n- `examplectl2 status`\n\n## License\n",
+			listed: true,
+		},
+		{
+			name:   "empty section",
+			readme: "# example\n\n### Commands\n\n## License\n",
+			cmd:    craft.Command{Binary: "example", Name: "status"},
+			want:   "# example\n\n### Commands\n- `example status`\n\n## License\n",
+			listed: true,
+		},
+		{
+			name:   "no section",
+			readme: "# example\n\n## License\n",
+			cmd:    craft.Command{Binary: "example", Name: "status"},
+			want:   "# example\n\n## License\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, listed := craft.ListCommand([]byte(tt.readme), tt.cmd)
+
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+
+			if listed != tt.listed {
+				t.Errorf("got listed %v, want %v", listed, tt.listed)
+			}
+		})
+	}
+}
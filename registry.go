@@ -0,0 +1,330 @@
+package craft
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GeneratorFunc maps destination paths to the templates rendering them.
+type GeneratorFunc func(data Data) (map[string]RenderOptions, error)
+
+// GeneratorOption configures a generator built with NewGenerator.
+type GeneratorOption func(*generator)
+
+// WithFeatures runs the generator only when the project includes at least
+// one of features.
+func WithFeatures(features ...string) GeneratorOption {
+	return func(g *generator) {
+		g.features = features
+	}
+}
+
+// DependsOn makes the generator run after, and along with, the named
+// generators.
+func DependsOn(generators ...string) GeneratorOption {
+	return func(g *generator) {
+		g.dependencies = generators
+	}
+}
+
+// WithValidation checks the data before the generator runs.
+func WithValidation(validate func(Data) error) GeneratorOption {
+	return func(g *generator) {
+		g.validate = validate
+	}
+}
+
+type generator struct {
+	name         string
+	description  string
+	features     []string
+	dependencies []string
+	validate     func(Data) error
+	generate     GeneratorFunc
+}
+
+// NewGenerator turns fn into a Generator.
+func NewGenerator(name, description string, fn GeneratorFunc, opts ...GeneratorOption) Generator {
+	g := &generator{
+		name:        name,
+		description: description,
+		generate:    fn,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+func (g *generator) Name() string           { return g.name }
+func (g *generator) Description() string    { return g.description }
+func (g *generator) Features() []string     { return g.features }
+func (g *generator) Dependencies() []string { return g.dependencies }
+
+func (g *generator) Validate(data Data) error {
+	if g.validate == nil {
+		return nil
+	}
+
+	return g.validate(data)
+}
+
+func (g *generator) Generate(data Data) (map[string]RenderOptions, error) {
+	return g.generate(data)
+}
+
+// Registry holds the generators known to a Manager.
+type Registry struct {
+	generators map[string]Generator
+}
+
+// NewRegistry creates a registry holding generators.
+func NewRegistry(generators ...Generator) (*Registry, error) {
+	r := &Registry{generators: make(map[string]Generator)}
+
+	for _, g := range generators {
+		if err := r.Register(g); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// DefaultRegistry returns a registry with the generators built into craft.
+func DefaultRegistry() *Registry {
+	r, err := NewRegistry(
+		NewGenerator("common", "Repository files: README, go.mod, .gitignore and friends", GenerateCommonFiles),
+		NewGenerator("config", "Configuration loading package and default config", GenerateConfig),
+		NewGenerator("version", "Build version package", GenerateVersion),
+		NewGenerator("commands", "CLI commands and entry points", GenerateCommands,
+			WithFeatures("cli"),
+			DependsOn("version"),
+			WithValidation(validateFramework),
+		),
+		NewGenerator("docker", "Dockerfiles and compose services", GenerateDockerFiles,
+			WithFeatures("docker", "mysql", "postgres", "mariadb", "redis", "grafana", "prometheus", "rabbitmq", "jaeger", "kafka", "minio", "localstack"),
+		),
+		NewGenerator("script", "Build scripts, deployment manifests and CI pipelines", GenerateScripts),
+		NewGenerator("license", "LICENSE file", GenerateLicense,
+			WithValidation(validateLicense),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Register adds g to the registry. Names must be unique.
+func (r *Registry) Register(g Generator) error {
+	if _, ok := r.generators[g.Name()]; ok {
+		return fmt.Errorf("generator %s is already registered", g.Name())
+	}
+
+	r.generators[g.Name()] = g
+
+	return nil
+}
+
+// Get returns the generator called name.
+func (r *Registry) Get(name string) (Generator, bool) {
+	g, ok := r.generators[name]
+
+	return g, ok
+}
+
+// Names returns the sorted names of the registered generators.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.generators))
+	for name := range r.generators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Select returns the names of the generators to run: only, or every
+// registered generator when only is empty, without those in skip. Unknown
+// names are rejected, and so is skipping a dependency of a selected
+// generator.
+func (r *Registry) Select(only, skip []string) ([]string, error) {
+	for _, name := range append(append([]string{}, only...), skip...) {
+		if _, ok := r.generators[name]; !ok {
+			return nil, fmt.Errorf("unknown generator %q, available generators: %s", name, strings.Join(r.Names(), ", "))
+		}
+	}
+
+	names := only
+	if len(names) == 0 {
+		names = r.Names()
+	}
+
+	var selected []string
+
+	for _, name := range names {
+		if !contains(skip, name) && !contains(selected, name) {
+			selected = append(selected, name)
+		}
+	}
+
+	for _, name := range selected {
+		for _, dep := range r.generators[name].Dependencies() {
+			if contains(skip, dep) {
+				return nil, fmt.Errorf("cannot skip generator %s, generator %s depends on it", dep, name)
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// Skipped explains why a generator does not run.
+type Skipped struct {
+	Generator string
+	Reason    string
+}
+
+// Resolve returns the generators to run for data, in dependency order, and
+// the generators left out along with the reason. Dependencies of a selected
+// generator are selected as well. Generators whose features are not
+// included are skipped, and so are the generators depending on them.
+func (r *Registry) Resolve(data Data, names ...string) ([]Generator, []Skipped, error) {
+	selected := make(map[string]bool)
+
+	var selectWithDeps func(name string) error
+	selectWithDeps = func(name string) error {
+		g, ok := r.generators[name]
+		if !ok {
+			return fmt.Errorf("unknown generator %q, available generators: %s", name, strings.Join(r.Names(), ", "))
+		}
+
+		if selected[name] {
+			return nil
+		}
+		selected[name] = true
+
+		for _, dep := range g.Dependencies() {
+			if err := selectWithDeps(dep); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := selectWithDeps(name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	order, err := r.sort(selected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		run     []Generator
+		skipped []Skipped
+	)
+
+	skip := make(map[string]bool)
+
+	for _, name := range r.Names() {
+		if !selected[name] {
+			skipped = append(skipped, Skipped{Generator: name, Reason: "not selected"})
+		}
+	}
+
+	for _, g := range order {
+		if features := g.Features(); !data.hasAnyFeature(features) {
+			skip[g.Name()] = true
+			skipped = append(skipped, Skipped{
+				Generator: g.Name(),
+				Reason:    fmt.Sprintf("none of the features %s is included", strings.Join(features, ", ")),
+			})
+
+			continue
+		}
+
+		var missing []string
+		for _, dep := range g.Dependencies() {
+			if skip[dep] {
+				missing = append(missing, dep)
+			}
+		}
+
+		if len(missing) > 0 {
+			skip[g.Name()] = true
+			skipped = append(skipped, Skipped{
+				Generator: g.Name(),
+				Reason:    fmt.Sprintf("depends on skipped generator %s", strings.Join(missing, ", ")),
+			})
+
+			continue
+		}
+
+		run = append(run, g)
+	}
+
+	return run, skipped, nil
+}
+
+// sort orders the selected generators so that every generator comes after
+// its dependencies, breaking ties by name.
+func (r *Registry) sort(selected map[string]bool) ([]Generator, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[string]int)
+	order := make([]Generator, 0, len(selected))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle between generators: %s", strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+
+		deps := append([]string(nil), r.generators[name].Dependencies()...)
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			if err := visit(dep, append(path[:len(path):len(path)], name)); err != nil {
+				return err
+			}
+		}
+
+		state[name] = visited
+		order = append(order, r.generators[name])
+
+		return nil
+	}
+
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,277 @@
+package craft_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+// stubGenerator returns a generator writing its name to each of paths.
+func stubGenerator(name string, paths []string, opts ...craft.GeneratorOption) craft.Generator {
+	return craft.NewGenerator(name, "Stub "+name, func(craft.Data) (map[string]craft.RenderOptions, error) {
+		out := make(map[string]craft.RenderOptions, len(paths))
+		for _, path := range paths {
+			out[path] = craft.RenderOptions{Content: []byte(name + "\n")}
+		}
+
+		return out, nil
+	}, opts...)
+}
+
+func generatorNames(generators []craft.Generator) []string {
+	names := make([]string, 0, len(generators))
+	for _, g := range generators {
+		names = append(names, g.Name())
+	}
+
+	return names
+}
+
+func TestRegistryResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		generators []craft.Generator
+		includes   []string
+		selected   []string
+		run        []string
+		skipped    map[string]string
+		err        string
+	}{
+		{
+			name: "dependencies first",
+			generators: []craft.Generator{
+				stubGenerator("app", nil, craft.DependsOn("config", "version")),
+				stubGenerator("config", nil, craft.DependsOn("version")),
+				stubGenerator("version", nil),
+				stubGenerator("zzz", nil),
+			},
+			selected: []string{"app", "zzz"},
+			run:      []string{"version", "config", "app", "zzz"},
+			skipped:  map[string]string{},
+		},
+		{
+			name: "dependencies selected along",
+			generators: []craft.Generator{
+				stubGenerator("app", nil, craft.DependsOn("version")),
+				stubGenerator("version", nil),
+				stubGenerator("other", nil),
+			},
+			selected: []string{"app"},
+			run:      []string{"version", "app"},
+			skipped:  map[string]string{"other": "not selected"},
+		},
+		{
+			name: "features",
+			generators: []craft.Generator{
+				stubGenerator("docker", nil, craft.WithFeatures("docker", "postgres")),
+				stubGenerator("k8s", nil, craft.WithFeatures("k8s")),
+			},
+			includes: []string{"postgres"},
+			selected: []string{"docker", "k8s"},
+			run:      []string{"docker"},
+			skipped:  map[string]string{"k8s": "none of the features k8s is included"},
+		},
+		{
+			name: "dependents of a skipped generator",
+			generators: []craft.Generator{
+				stubGenerator("commands", nil, craft.WithFeatures("cli")),
+				stubGenerator("server", nil, craft.DependsOn("commands")),
+				stubGenerator("daemon", nil, craft.DependsOn("server")),
+				stubGenerator("common", nil),
+			},
+			includes: []string{"docker"},
+			selected: []string{"commands", "server", "daemon", "common"},
+			run:      []string{"common"},
+			skipped: map[string]string{
+				"commands": "none of the features cli is included",
+				"server":   "depends on skipped generator commands",
+				"daemon":   "depends on skipped generator server",
+			},
+		},
+		{
+			name: "cycle",
+			generators: []craft.Generator{
+				stubGenerator("a", nil, craft.DependsOn("b")),
+				stubGenerator("b", nil, craft.DependsOn("c")),
+				stubGenerator("c", nil, craft.DependsOn("a")),
+			},
+			selected: []string{"a"},
+			err:      "dependency cycle between generators: a -> b -> c -> a",
+		},
+		{
+			name: "unknown generator",
+			generators: []craft.Generator{
+				stubGenerator("a", nil),
+			},
+			selected: []string{"b"},
+			err:      `unknown generator "b", available generators: a`,
+		},
+		{
+			name: "unknown dependency",
+			generators: []craft.Generator{
+				stubGenerator("a", nil, craft.DependsOn("b")),
+			},
+			selected: []string{"a"},
+			err:      `unknown generator "b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := craft.NewRegistry(tt.generators...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := testData()
+			data.Includes = tt.includes
+
+			run, skipped, err := registry.Resolve(data, tt.selected...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := generatorNames(run); !reflect.DeepEqual(got, tt.run) {
+				t.Errorf("got run %v, want %v", got, tt.run)
+			}
+
+			reasons := make(map[string]string, len(skipped))
+			for _, s := range skipped {
+				reasons[s.Generator] = s.Reason
+			}
+
+			if !reflect.DeepEqual(reasons, tt.skipped) {
+				t.Errorf("got skipped %v, want %v", reasons, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	_, err := craft.NewRegistry(stubGenerator("a", nil), stubGenerator("a", nil))
+	if err == nil || !strings.Contains(err.Error(), "generator a is already registered") {
+		t.Errorf("got error %v, want a duplicate generator", err)
+	}
+}
+
+func TestRenderGenerators(t *testing.T) {
+	tests := []struct {
+		name       string
+		generators []craft.Generator
+		want       map[string]string
+		err        []string
+	}{
+		{
+			name: "files of every generator",
+			generators: []craft.Generator{
+				stubGenerator("a", []string{"a.txt", "shared/a.txt"}),
+				stubGenerator("b", []string{"b.txt"}, craft.DependsOn("a")),
+			},
+			want: map[string]string{"a.txt": "a", "shared/a.txt": "a", "b.txt": "b"},
+		},
+		{
+			name: "duplicate output paths",
+			generators: []craft.Generator{
+				stubGenerator("a", []string{"a.txt", "shared.txt", "both.txt"}),
+				stubGenerator("b", []string{"shared.txt", "both.txt"}),
+			},
+			err: []string{
+				"both.txt is generated by both a and b",
+				"shared.txt is generated by both a and b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := craft.NewRegistry(tt.generators...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			manager := craft.Manager{Registry: registry}
+
+			files, err := manager.Render(context.Background(), testData(), registry.Names()...)
+			if tt.err != nil {
+				assertErrors(t, err, tt.err)
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string, len(files))
+			for path, f := range files {
+				got[path] = strings.TrimSpace(string(f.Content))
+
+				if f.Generator != got[path] {
+					t.Errorf("%s: got generator %s, want %s", path, f.Generator, got[path])
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got files %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistrySelect(t *testing.T) {
+	registry, err := craft.NewRegistry(
+		stubGenerator("app", nil, craft.DependsOn("version")),
+		stubGenerator("license", nil),
+		stubGenerator("version", nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		only, skip []string
+		want       []string
+		err        string
+	}{
+		{name: "every generator", want: []string{"app", "license", "version"}},
+		{name: "only", only: []string{"license", "app", "license"}, want: []string{"license", "app"}},
+		{name: "skip", skip: []string{"license"}, want: []string{"app", "version"}},
+		{name: "only and skip", only: []string{"app", "license"}, skip: []string{"license"}, want: []string{"app"}},
+		{name: "unknown only", only: []string{"docs"}, err: `unknown generator "docs", available generators: app, license, version`},
+		{name: "unknown skip", skip: []string{"docs"}, err: `unknown generator "docs", available generators: app, license, version`},
+		{name: "skipped dependency", skip: []string{"version"}, err: "cannot skip generator version, generator app depends on it"},
+		{name: "skipped dependency of only", only: []string{"app"}, skip: []string{"version"}, err: "cannot skip generator version, generator app depends on it"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Select(tt.only, tt.skip)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package craft
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// Sink receives generated files. Nothing is guaranteed to be visible before
+// Commit, and Rollback discards whatever was written. A *Transaction is the
+// sink writing to a directory.
+type Sink interface {
+	Write(path string, content []byte, mode fs.FileMode) error
+	Commit(ctx context.Context) error
+	Rollback() error
+}
+
+// WriteFiles writes files to sink in path order and commits them, rolling
+// back when any of them fails.
+func WriteFiles(ctx context.Context, sink Sink, files map[string]File) error {
+	defer sink.Rollback()
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		f := files[p]
+
+		mode := f.Mode
+		if mode == 0 {
+			mode = DefaultFileMode
+		}
+
+		if err := sink.Write(p, f.Content, mode); err != nil {
+			return err
+		}
+	}
+
+	return sink.Commit(ctx)
+}
+
+type bufferedFile struct {
+	path    string
+	content []byte
+	mode    fs.FileMode
+}
+
+// bufferedSink holds the files in memory until Commit hands them to flush.
+type bufferedSink struct {
+	root  string
+	files []bufferedFile
+	done  bool
+	flush func([]bufferedFile) error
+}
+
+func (s *bufferedSink) Write(p string, content []byte, mode fs.FileMode) error {
+	if s.done {
+		return errors.New("sink already committed")
+	}
+
+	s.files = append(s.files, bufferedFile{
+		path:    path.Join(s.root, p),
+		content: content,
+		mode:    mode,
+	})
+
+	return nil
+}
+
+func (s *bufferedSink) Commit(ctx context.Context) error {
+	if s.done {
+		return errors.New("sink already committed")
+	}
+	s.done = true
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.flush(s.files)
+}
+
+func (s *bufferedSink) Rollback() error {
+	s.done = true
+	s.files = nil
+
+	return nil
+}
+
+// NewTarGzSink writes the files as a gzipped tarball to w, under the root
+// directory.
+func NewTarGzSink(w io.Writer, root string) Sink {
+	return &bufferedSink{root: root, flush: func(files []bufferedFile) error {
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		now := time.Now()
+
+		for _, f := range files {
+			hdr := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     f.path,
+				Mode:     int64(f.mode.Perm()),
+				Size:     int64(len(f.content)),
+				ModTime:  now,
+			}
+
+			if err := tw.WriteHeader(hdr); err != nil {
+				return fmt.Errorf("failed to add %s to archive: %w", f.path, err)
+			}
+
+			if _, err := tw.Write(f.content); err != nil {
+				return fmt.Errorf("failed to add %s to archive: %w", f.path, err)
+			}
+		}
+
+		if err := tw.Close(); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+
+		return gw.Close()
+	}}
+}
+
+// NewZipSink writes the files as a zip archive to w, under the root
+// directory.
+func NewZipSink(w io.Writer, root string) Sink {
+	return &bufferedSink{root: root, flush: func(files []bufferedFile) error {
+		zw := zip.NewWriter(w)
+		now := time.Now()
+
+		for _, f := range files {
+			hdr := &zip.FileHeader{
+				Name:     f.path,
+				Method:   zip.Deflate,
+				Modified: now,
+			}
+			hdr.SetMode(f.mode)
+
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return fmt.Errorf("failed to add %s to archive: %w", f.path, err)
+			}
+
+			if _, err := fw.Write(f.content); err != nil {
+				return fmt.Errorf("failed to add %s to archive: %w", f.path, err)
+			}
+		}
+
+		return zw.Close()
+	}}
+}
+
+// NewTxtarSink writes the files to w in the txtar format, one
+// "-- path --" header per file. File modes are not recorded.
+func NewTxtarSink(w io.Writer, root string) Sink {
+	return &bufferedSink{root: root, flush: func(files []bufferedFile) error {
+		for _, f := range files {
+			content := f.content
+			if len(content) > 0 && content[len(content)-1] != '\n' {
+				content = append(content[:len(content):len(content)], '\n')
+			}
+
+			if _, err := fmt.Fprintf(w, "-- %s --\n%s", f.path, content); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}}
+}
+
+// MemSink keeps the files in memory. They are readable through FS once
+// committed.
+type MemSink struct {
+	bufferedSink
+	fs memFS
+}
+
+// NewMemSink creates an empty in-memory sink.
+func NewMemSink() *MemSink {
+	s := &MemSink{fs: make(memFS)}
+	s.flush = func(files []bufferedFile) error {
+		now := time.Now()
+
+		for _, f := range files {
+			s.fs[f.path] = &memFile{data: f.content, mode: f.mode, modTime: now}
+		}
+
+		return nil
+	}
+
+	return s
+}
+
+// FS returns the committed files.
+func (s *MemSink) FS() fs.FS {
+	return s.fs
+}
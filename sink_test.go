@@ -0,0 +1,198 @@
+package craft_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func sinkFiles() map[string]craft.File {
+	return map[string]craft.File{
+		"README.md":           {Path: "README.md", Content: []byte("# example\n")},
+		"scripts/build.sh":    {Path: "scripts/build.sh", Content: []byte("#!/bin/sh\n"), Mode: 0755},
+		"cmd/example/main.go": {Path: "cmd/example/main.go", Content: []byte("package main")},
+	}
+}
+
+// archived is a file read back from an archive.
+type archived struct {
+	content string
+	mode    fs.FileMode
+}
+
+func sinkWant() map[string]archived {
+	return map[string]archived{
+		"example/README.md":           {"# example\n", craft.DefaultFileMode},
+		"example/cmd/example/main.go": {"package main", craft.DefaultFileMode},
+		"example/scripts/build.sh":    {"#!/bin/sh\n", 0755},
+	}
+}
+
+func TestTarGzSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := craft.WriteFiles(context.Background(), craft.NewTarGzSink(&buf, "example"), sinkFiles()); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]archived)
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got[hdr.Name] = archived{string(content), fs.FileMode(hdr.Mode)}
+	}
+
+	assertArchived(t, got, sinkWant())
+}
+
+func TestZipSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := craft.WriteFiles(context.Background(), craft.NewZipSink(&buf, "example"), sinkFiles()); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]archived)
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got[f.Name] = archived{string(content), f.Mode()}
+	}
+
+	assertArchived(t, got, sinkWant())
+}
+
+func assertArchived(t *testing.T, got, want map[string]archived) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("got %d files, want %d", len(got), len(want))
+	}
+
+	for name, w := range want {
+		if g, ok := got[name]; !ok {
+			t.Errorf("%s is missing", name)
+		} else if g != w {
+			t.Errorf("got %s %q with mode %v, want %q with mode %v", name, g.content, g.mode, w.content, w.mode)
+		}
+	}
+}
+
+func TestTxtarSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := craft.WriteFiles(context.Background(), craft.NewTxtarSink(&buf, "example"), sinkFiles()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files are written in path order, with a newline added to the files
+	// missing one.
+	want := "-- example/README.md --\n# example\n" +
+		"-- example/cmd/example/main.go --\npackage main\n" +
+		"-- example/scripts/build.sh --\n#!/bin/sh\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMemSink(t *testing.T) {
+	sink := craft.NewMemSink()
+
+	if err := sink.Write("README.md", []byte("# example\n"), craft.DefaultFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.Stat(sink.FS(), "README.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got %v before commit, want %v", err, fs.ErrNotExist)
+	}
+
+	if err := sink.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Write("LICENSE", nil, craft.DefaultFileMode); err == nil {
+		t.Error("got no error writing to a committed sink")
+	}
+
+	sink = craft.NewMemSink()
+
+	if err := craft.WriteFiles(context.Background(), sink, sinkFiles()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(sink.FS(), "README.md", "cmd/example/main.go", "scripts/build.sh"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat(sink.FS(), "scripts/build.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode() != 0755 {
+		t.Errorf("got mode %v, want %v", info.Mode(), fs.FileMode(0755))
+	}
+}
+
+func TestMemSinkRollback(t *testing.T) {
+	sink := craft.NewMemSink()
+
+	if err := sink.Write("README.md", []byte("# example\n"), craft.DefaultFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Commit(context.Background()); err == nil {
+		t.Error("got no error committing a rolled back sink")
+	}
+
+	if entries, err := fs.ReadDir(sink.FS(), "."); err != nil || len(entries) != 0 {
+		t.Errorf("got %v, %v after rollback, want no files", entries, err)
+	}
+}
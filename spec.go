@@ -0,0 +1,265 @@
+package craft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes a project in a file, usually craft.yaml, kept in the
+// repository as the source of truth of the project. It is read from YAML,
+// JSON or TOML.
+type Spec struct {
+	Name         string    `json:"name" yaml:"name" toml:"name"`
+	Module       string    `json:"module" yaml:"module" toml:"module"`
+	Description  string    `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Author       string    `json:"author,omitempty" yaml:"author,omitempty" toml:"author,omitempty"`
+	License      string    `json:"license,omitempty" yaml:"license,omitempty" toml:"license,omitempty"`
+	GoVersion    string    `json:"go,omitempty" yaml:"go,omitempty" toml:"go,omitempty"`
+	CLI          string    `json:"cli,omitempty" yaml:"cli,omitempty" toml:"cli,omitempty"`
+	Binaries     []string  `json:"binaries,omitempty" yaml:"binaries,omitempty" toml:"binaries,omitempty"`
+	Commands     []Command `json:"commands,omitempty" yaml:"commands,omitempty" toml:"commands,omitempty"`
+	Includes     []string  `json:"includes,omitempty" yaml:"includes,omitempty" toml:"includes,omitempty"`
+	ConfigDirs   []string  `json:"config_dirs,omitempty" yaml:"config_dirs,omitempty" toml:"config_dirs,omitempty"`
+	ConfigFile   string    `json:"config_file,omitempty" yaml:"config_file,omitempty" toml:"config_file,omitempty"`
+	ConfigFormat string    `json:"config_format,omitempty" yaml:"config_format,omitempty" toml:"config_format,omitempty"`
+	EnvPrefix    string    `json:"env_prefix,omitempty" yaml:"env_prefix,omitempty" toml:"env_prefix,omitempty"`
+}
+
+// DefaultSpec returns the values used for the settings a spec leaves out.
+func DefaultSpec() Spec {
+	return Spec{
+		License:      "mit",
+		GoVersion:    "1.21",
+		CLI:          "cobra",
+		ConfigFile:   "config.yml",
+		ConfigFormat: "yml",
+	}
+}
+
+// LoadSpec reads the spec at path, in the format given by its extension, on
+// top of DefaultSpec.
+func LoadSpec(path string) (Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	spec, err := ParseSpec(content, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return Spec{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// ParseSpec decodes a spec in format, one of yaml, yml, json or toml, on top
+// of DefaultSpec. Unknown settings are rejected.
+func ParseSpec(content []byte, format string) (Spec, error) {
+	spec := DefaultSpec()
+
+	switch format {
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+
+		if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+			return Spec{}, fmt.Errorf("invalid spec: %w", err)
+		}
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&spec); err != nil {
+			return Spec{}, fmt.Errorf("invalid spec: %w", err)
+		}
+	case "toml":
+		md, err := toml.Decode(string(content), &spec)
+		if err != nil {
+			return Spec{}, fmt.Errorf("invalid spec: %w", err)
+		}
+
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return Spec{}, fmt.Errorf("invalid spec: unknown setting %s", undecoded[0])
+		}
+	default:
+		return Spec{}, fmt.Errorf("unsupported spec format %q, expected yaml, json or toml", format)
+	}
+
+	return spec, nil
+}
+
+// envPrefixFor derives the env prefix of the project called name. Names may
+// start with a digit, which env prefixes may not, so the leading digits are
+// dropped, falling back to APP when nothing is left.
+func envPrefixFor(name string) string {
+	prefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	prefix = strings.TrimLeft(prefix, "0123456789_")
+
+	if prefix == "" {
+		return "APP"
+	}
+
+	return prefix
+}
+
+// Data turns the spec into the data of the generators, deriving the
+// settings left empty from the project name.
+func (s Spec) Data() Data {
+	configDirs := s.ConfigDirs
+	if len(configDirs) == 0 {
+		configDirs = []string{
+			fmt.Sprintf("/etc/%s", s.Name),
+			fmt.Sprintf("$HOME/.config/%s", s.Name),
+		}
+	}
+
+	prefix := s.EnvPrefix
+	if prefix == "" {
+		prefix = envPrefixFor(s.Name)
+	}
+
+	binaries := s.Binaries
+	if len(binaries) == 0 {
+		binaries = []string{s.Name}
+	}
+
+	description := s.Description
+	if description == "" {
+		description = s.Name
+	}
+
+	includes := s.Includes
+	if includes == nil {
+		includes = []string{}
+	}
+
+	commands := s.Commands
+	if commands == nil {
+		commands = []Command{}
+	}
+
+	return Data{
+		ProjectName:  s.Name,
+		ModulePrefix: s.Module,
+		Binaries:     binaries,
+		Includes:     includes,
+		License:      s.License,
+		GoVersion:    s.GoVersion,
+		Author:       s.Author,
+		ConfigDirs:   configDirs,
+		ConfigFile:   s.ConfigFile,
+		ConfigFormat: s.ConfigFormat,
+		EnvPrefix:    prefix,
+		CLI: CLI{
+			Framework: s.CLI,
+		},
+		Module:      s.Module,
+		AppName:     s.Name,
+		Description: description,
+		Commands:    commands,
+	}
+}
+
+// Spec returns the spec of a project generated from d, such as the data
+// recorded in its lock file.
+func (d Data) Spec() Spec {
+	return Spec{
+		Name:         d.ProjectName,
+		Module:       d.ModulePrefix,
+		Description:  d.Description,
+		Author:       d.Author,
+		License:      d.License,
+		GoVersion:    d.GoVersion,
+		CLI:          d.Framework,
+		Binaries:     d.Binaries,
+		Commands:     d.Commands,
+		Includes:     d.Includes,
+		ConfigDirs:   d.ConfigDirs,
+		ConfigFile:   d.ConfigFile,
+		ConfigFormat: d.ConfigFormat,
+		EnvPrefix:    d.EnvPrefix,
+	}
+}
+
+// StarterSpec returns a commented YAML spec for a project called name, to
+// be completed by hand.
+func StarterSpec(name, module string) []byte {
+	d := DefaultSpec()
+
+	if name == "" {
+		name = "myapp"
+	}
+
+	if module == "" {
+		module = "github.com/username/" + name
+	}
+
+	return []byte(fmt.Sprintf(`# Project spec for craft, generate the project with:
+#
+#   craft new -f craft.yaml
+#
+# Flags given to craft new override the values below.
+
+# Name of the project, and of the directory it is generated in.
+name: %s
+
+# Go module path.
+module: %s
+
+# One-line description, defaults to the name.
+# description: ""
+
+# Copyright holder.
+# author: ""
+
+# License, see craft list licenses.
+license: %s
+
+# Go version written to go.mod.
+go: "%s"
+
+# CLI framework, cobra or urfave.
+cli: %s
+
+# Binaries, each with its own cmd/<binary> entry point. Defaults to the
+# project name.
+binaries:
+  - %s
+
+# Commands added to the binaries, see craft add command.
+# commands:
+#   - binary: %s
+#     name: migrate
+#     flags:
+#       - name: dry-run
+#         type: bool
+
+# Features to include, see craft list features. Every feature is included
+# when the list is empty.
+# includes:
+#   - cli
+#   - docker
+#   - github
+
+# Directories searched for the configuration file, defaults to
+# /etc/<name> and $HOME/.config/<name>.
+# config_dirs: []
+
+config_file: %s
+
+# Format of the configuration file: yml, yaml, json or toml.
+config_format: %s
+
+# Prefix of the environment variables, defaults to the name in upper case.
+# env_prefix: ""
+`, name, module, d.License, d.GoVersion, d.CLI, name, name, d.ConfigFile, d.ConfigFormat))
+}
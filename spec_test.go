@@ -0,0 +1,172 @@
+package craft_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestParseSpec(t *testing.T) {
+	want := craft.DefaultSpec()
+	want.Name = "example"
+	want.Module = "github.com/example/example"
+	want.Binaries = []string{"examplectl", "exampled"}
+	want.Includes = []string{"docker"}
+
+	specs := map[string]string{
+		"yaml": "name: example\nmodule: github.com/example/example\nbinaries: [examplectl, exampled]\nincludes: [docker]\n",
+		"json": `{"name": "example", "module": "github.com/example/example", "binaries": ["examplectl", "exampled"], "includes": ["docker"]}`,
+		"toml": "name = \"example\"\nmodule = \"github.com/example/example\"\nbinaries = [\"examplectl\", \"exampled\"]\nincludes = [\"docker\"]\n",
+	}
+
+	for format, content := range specs {
+		t.Run(format, func(t *testing.T) {
+			got, err := craft.ParseSpec([]byte(content), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The settings left out keep their defaults.
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseSpecOverridesDefaults(t *testing.T) {
+	got, err := craft.ParseSpec([]byte("name: example\nlicense: apache-2.0\ncli: urfave\n"), "yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.License != "apache-2.0" || got.CLI != "urfave" || got.GoVersion != craft.DefaultSpec().GoVersion {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestParseSpecEmpty(t *testing.T) {
+	got, err := craft.ParseSpec(nil, "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, craft.DefaultSpec()) {
+		t.Errorf("got %+v, want the defaults", got)
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+		want    string
+	}{
+		{format: "yaml", content: "name: example\nlicence: mit\n", want: "field licence not found"},
+		{format: "json", content: `{"name": "example", "licence": "mit"}`, want: `unknown field "licence"`},
+		{format: "toml", content: "name = \"example\"\nlicence = \"mit\"\n", want: "unknown setting licence"},
+		{format: "yaml", content: "name: [\n", want: "invalid spec"},
+		{format: "ini", content: "name = example\n", want: `unsupported spec format "ini"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.want, func(t *testing.T) {
+			if _, err := craft.ParseSpec([]byte(tt.content), tt.format); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "craft.toml", []byte("name = \"example\"\n"))
+	writeFile(t, dir, "craft.yaml", []byte("name: example\nunknown: true\n"))
+
+	spec, err := craft.LoadSpec(filepath.Join(dir, "craft.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Name != "example" {
+		t.Errorf("got name %q, want example", spec.Name)
+	}
+
+	// Errors name the spec file.
+	path := filepath.Join(dir, "craft.yaml")
+	if _, err := craft.LoadSpec(path); err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("got error %v, want it to start with %s", err, path)
+	}
+}
+
+func TestSpecData(t *testing.T) {
+	spec := craft.DefaultSpec()
+	spec.Name = "my-app.io"
+	spec.Module = "example.com/my-app"
+
+	data := spec.Data()
+
+	want := craft.Data{
+		ProjectName:  "my-app.io",
+		ModulePrefix: "example.com/my-app",
+		Module:       "example.com/my-app",
+		AppName:      "my-app.io",
+		Description:  "my-app.io",
+		GoVersion:    spec.GoVersion,
+		License:      spec.License,
+		ConfigDirs:   []string{"/etc/my-app.io", "$HOME/.config/my-app.io"},
+		ConfigFile:   spec.ConfigFile,
+		ConfigFormat: spec.ConfigFormat,
+		EnvPrefix:    "MY_APP_IO",
+		Binaries:     []string{"my-app.io"},
+		Includes:     []string{},
+		Commands:     []craft.Command{},
+		CLI:          craft.CLI{Framework: spec.CLI},
+	}
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %+v, want %+v", data, want)
+	}
+
+	// The settings given are kept, and the data turns back into the spec.
+	spec.Binaries = []string{"myctl"}
+	spec.EnvPrefix = "MY"
+	spec.Description = "My app"
+	spec.Includes = []string{"docker"}
+	spec.ConfigDirs = []string{"/etc/my"}
+	spec.Commands = []craft.Command{{Binary: "myctl", Name: "migrate"}}
+
+	if got := spec.Data().Spec(); !reflect.DeepEqual(got, spec) {
+		t.Errorf("got spec %+v, want %+v", got, spec)
+	}
+}
+
+func TestSpecDataEnvPrefix(t *testing.T) {
+	tests := map[string]string{
+		"example":   "EXAMPLE",
+		"my-app.io": "MY_APP_IO",
+		"app2":      "APP2",
+		"3d-viewer": "D_VIEWER",
+		"1_2-tool":  "TOOL",
+		"123":       "APP",
+	}
+
+	for name, want := range tests {
+		spec := craft.DefaultSpec()
+		spec.Name = name
+		spec.Module = "example.com/" + name
+		spec.Binaries = []string{"app"}
+
+		data := spec.Data()
+		if data.EnvPrefix != want {
+			t.Errorf("%s: got env prefix %q, want %q", name, data.EnvPrefix, want)
+		}
+
+		if err := data.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package craft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Transaction writes a set of files into a directory all at once. Files are
+// staged in a temporary directory next to the target and only moved into
+// place by Commit. When the target does not exist yet, the whole staging
+// directory is renamed into place atomically; otherwise files are moved one
+// by one, keeping backups of the files they replace so that a failure
+// restores the directory to its previous state.
+type Transaction struct {
+	dir     string
+	staging string
+	files   []string
+	done    bool
+}
+
+// Begin starts a transaction writing into dir.
+func Begin(dir string) (*Transaction, error) {
+	dir = filepath.Clean(dir)
+
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, ".craft-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	return &Transaction{dir: dir, staging: staging}, nil
+}
+
+func (t *Transaction) stagedPath(path string) string {
+	return filepath.Join(t.staging, "new", path)
+}
+
+func (t *Transaction) backupPath(path string) string {
+	return filepath.Join(t.staging, "old", path)
+}
+
+// Write stages a file at path, relative to the target directory.
+func (t *Transaction) Write(path string, content []byte, mode fs.FileMode) error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+
+	staged := t.stagedPath(path)
+
+	if err := os.MkdirAll(filepath.Dir(staged), 0755); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(staged, content, mode); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	// WriteFile is subject to the umask.
+	if err := os.Chmod(staged, mode); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	t.files = append(t.files, path)
+
+	return nil
+}
+
+// Commit moves the staged files into place. It stops and rolls back when ctx
+// is cancelled before every file is in place.
+func (t *Transaction) Commit(ctx context.Context) error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	t.done = true
+
+	defer os.RemoveAll(t.staging)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(t.dir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(t.stagedPath("."), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", t.dir, err)
+		}
+
+		if err := os.Rename(t.stagedPath("."), t.dir); err != nil {
+			return fmt.Errorf("failed to move %s into place: %w", t.dir, err)
+		}
+
+		return nil
+	}
+
+	sort.Strings(t.files)
+
+	var (
+		applied     []string
+		backedUp    = make(map[string]bool)
+		createdDirs []string
+	)
+
+	rollback := func(cause error) error {
+		for i := len(applied) - 1; i >= 0; i-- {
+			path := applied[i]
+			target := filepath.Join(t.dir, path)
+
+			os.Remove(target)
+			if backedUp[path] {
+				os.Rename(t.backupPath(path), target)
+			}
+		}
+
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			os.Remove(createdDirs[i])
+		}
+
+		return cause
+	}
+
+	for _, path := range t.files {
+		if err := ctx.Err(); err != nil {
+			return rollback(err)
+		}
+
+		target := filepath.Join(t.dir, path)
+
+		dirs, err := mkdirAll(filepath.Dir(target))
+		createdDirs = append(createdDirs, dirs...)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to create directory for %s: %w", path, err))
+		}
+
+		if _, err := os.Lstat(target); err == nil {
+			if err := os.MkdirAll(filepath.Dir(t.backupPath(path)), 0755); err != nil {
+				return rollback(fmt.Errorf("failed to back up %s: %w", path, err))
+			}
+
+			if err := os.Rename(target, t.backupPath(path)); err != nil {
+				return rollback(fmt.Errorf("failed to back up %s: %w", path, err))
+			}
+
+			backedUp[path] = true
+		}
+
+		applied = append(applied, path)
+
+		if err := os.Rename(t.stagedPath(path), target); err != nil {
+			return rollback(fmt.Errorf("failed to move %s into place: %w", path, err))
+		}
+	}
+
+	return nil
+}
+
+// Rollback discards the staged files. It does nothing once the transaction
+// is committed, so it is safe to defer.
+func (t *Transaction) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	return os.RemoveAll(t.staging)
+}
+
+// mkdirAll is like os.MkdirAll, but returns the directories it created,
+// outermost first.
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		}
+
+		missing = append([]string{d}, missing...)
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	var created []string
+
+	for _, d := range missing {
+		if err := os.Mkdir(d, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return created, err
+		}
+
+		created = append(created, d)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,138 @@
+package craft_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "example")
+
+	tx, err := craft.Begin(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := craft.WriteFiles(context.Background(), tx, sinkFiles()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, dir, "README.md", "# example\n")
+	assertFile(t, dir, "cmd/example/main.go", "package main")
+
+	info, err := os.Stat(filepath.Join(dir, "scripts", "build.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0755))
+	}
+
+	assertNoStaging(t, filepath.Dir(dir))
+}
+
+func TestTransactionRollback(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "example")
+
+	tx, err := craft.Begin(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Write("README.md", []byte("# example\n"), craft.DefaultFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(context.Background()); err == nil {
+		t.Error("got no error committing a rolled back transaction")
+	}
+
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want %s not to exist", err, dir)
+	}
+
+	assertNoStaging(t, filepath.Dir(dir))
+}
+
+func TestTransactionCancelled(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", []byte("old\n"))
+
+	tx, err := craft.Begin(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := craft.WriteFiles(ctx, tx, sinkFiles()); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	assertFile(t, dir, "README.md", "old\n")
+	assertNoStaging(t, filepath.Dir(dir))
+}
+
+// A failure halfway through the commit restores the files replaced so far
+// and removes the files and directories created.
+func TestTransactionCommitFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", []byte("old\n"))
+	// A file standing where a directory is needed fails the commit.
+	writeFile(t, dir, "scripts", []byte("not a directory\n"))
+
+	tx, err := craft.Begin(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := craft.WriteFiles(context.Background(), tx, sinkFiles()); err == nil {
+		t.Fatal("got no error")
+	}
+
+	assertFile(t, dir, "README.md", "old\n")
+	assertFile(t, dir, "scripts", "not a directory\n")
+
+	if _, err := os.Stat(filepath.Join(dir, "cmd")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want cmd to be removed", err)
+	}
+
+	assertNoStaging(t, filepath.Dir(dir))
+}
+
+func assertFile(t *testing.T, dir, path, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got %s %q, want %q", path, got, want)
+	}
+}
+
+func assertNoStaging(t *testing.T, parent string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(parent, ".craft-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) > 0 {
+		t.Errorf("staging directories left behind: %v", matches)
+	}
+}
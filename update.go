@@ -0,0 +1,118 @@
+package craft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// UpdateAction describes what an update does with a single file.
+type UpdateAction string
+
+const (
+	// UpdateCreated is a file generated for the first time.
+	UpdateCreated UpdateAction = "created"
+	// UpdateUpdated is a pristine file replaced by its new version.
+	UpdateUpdated UpdateAction = "updated"
+	// UpdateMerged is an edited file merged cleanly with its new version.
+	UpdateMerged UpdateAction = "merged"
+	// UpdateConflict is an edited file merged with conflict markers.
+	UpdateConflict UpdateAction = "conflict"
+	// UpdateUnchanged is a file that needs no change.
+	UpdateUnchanged UpdateAction = "unchanged"
+	// UpdateDeleted is a file removed by the user, which is left deleted.
+	UpdateDeleted UpdateAction = "deleted"
+	// UpdateObsolete is a file no longer produced by any generator. It is
+	// left on disk untouched.
+	UpdateObsolete UpdateAction = "obsolete"
+)
+
+// UpdateResult is the outcome of updating one file. Content holds what must
+// be written with Mode for created, updated, merged and conflicting files.
+type UpdateResult struct {
+	Path    string
+	Action  UpdateAction
+	Content []byte
+	Mode    fs.FileMode
+}
+
+// Update brings the project in dir forward to the freshly generated files,
+// using the merge bases kept under BaseDir to perform a three-way merge of
+// every file edited since it was generated. Without a base matching the
+// lock, the whole file conflicts. Nothing is written to disk.
+func Update(dir string, lock *Lock, files map[string]File) ([]UpdateResult, error) {
+	results := make([]UpdateResult, 0, len(files))
+
+	for path, f := range files {
+		result, err := updateFile(dir, lock, path, f)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	for _, locked := range lock.Files {
+		if _, ok := files[locked.Path]; !ok {
+			results = append(results, UpdateResult{Path: locked.Path, Action: UpdateObsolete})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	return results, nil
+}
+
+func updateFile(dir string, lock *Lock, path string, f File) (UpdateResult, error) {
+	result := UpdateResult{Path: path, Mode: f.Mode}
+	generated := f.Content
+
+	current, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return result, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	exists := err == nil
+
+	locked, tracked := lock.File(path)
+
+	switch {
+	case !exists && tracked:
+		result.Action = UpdateDeleted
+		return result, nil
+	case !exists:
+		result.Action = UpdateCreated
+		result.Content = generated
+		return result, nil
+	case f.CreateOnly, bytes.Equal(current, generated):
+		result.Action = UpdateUnchanged
+		return result, nil
+	case tracked && Hash(current) == locked.Hash:
+		result.Action = UpdateUpdated
+		result.Content = generated
+		return result, nil
+	case tracked && Hash(generated) == locked.Hash:
+		// Only the user changed the file.
+		result.Action = UpdateUnchanged
+		return result, nil
+	}
+
+	merged, conflict := Merge(lock.Base(dir, path), current, generated)
+	if bytes.Equal(merged, current) {
+		result.Action = UpdateUnchanged
+		return result, nil
+	}
+
+	result.Action = UpdateMerged
+	if conflict {
+		result.Action = UpdateConflict
+	}
+	result.Content = merged
+
+	return result, nil
+}
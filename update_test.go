@@ -0,0 +1,152 @@
+package craft_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestUpdate(t *testing.T) {
+	type file struct {
+		base      string // previously generated, recorded in the lock when set
+		current   string // on disk when set
+		generated string // freshly generated when set
+	}
+
+	tests := []struct {
+		name    string
+		file    file
+		noBase  bool
+		want    craft.UpdateAction
+		content string
+	}{
+		{
+			name:    "created",
+			file:    file{generated: "a\n"},
+			want:    craft.UpdateCreated,
+			content: "a\n",
+		},
+		{
+			name:    "updated",
+			file:    file{base: "a\n", current: "a\n", generated: "b\n"},
+			want:    craft.UpdateUpdated,
+			content: "b\n",
+		},
+		{
+			name:    "merged",
+			file:    file{base: "a\nx\nb\n", current: "A\nx\nb\n", generated: "a\nx\nB\n"},
+			want:    craft.UpdateMerged,
+			content: "A\nx\nB\n",
+		},
+		{
+			name:    "conflict",
+			file:    file{base: "a\n", current: "mine\n", generated: "theirs\n"},
+			want:    craft.UpdateConflict,
+			content: "<<<<<<< current\nmine\n||||||| base\na\n=======\ntheirs\n>>>>>>> generated\n",
+		},
+		{
+			name:    "conflict without base",
+			file:    file{base: "a\nx\nb\n", current: "A\nx\nb\n", generated: "a\nx\nB\n"},
+			noBase:  true,
+			want:    craft.UpdateConflict,
+			content: "<<<<<<< current\nA\nx\nb\n||||||| base\n=======\na\nx\nB\n>>>>>>> generated\n",
+		},
+		{
+			name: "unchanged",
+			file: file{base: "a\n", current: "a\n", generated: "a\n"},
+			want: craft.UpdateUnchanged,
+		},
+		{
+			name: "unchanged when only edited",
+			file: file{base: "a\n", current: "b\n", generated: "a\n"},
+			want: craft.UpdateUnchanged,
+		},
+		{
+			name: "deleted",
+			file: file{base: "a\n", generated: "b\n"},
+			want: craft.UpdateDeleted,
+		},
+		{
+			name: "obsolete",
+			file: file{base: "a\n", current: "a\n"},
+			want: craft.UpdateObsolete,
+		},
+	}
+
+	const path = "dir/file.txt"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			lock := craft.NewLock(craft.Data{}, nil)
+
+			if tt.file.base != "" {
+				previous := map[string]craft.File{path: {Path: path, Content: []byte(tt.file.base)}}
+				lock.Record(previous)
+
+				if !tt.noBase {
+					for _, f := range craft.BaseFiles(previous) {
+						writeFile(t, dir, f.Path, f.Content)
+					}
+				}
+			}
+
+			if tt.file.current != "" {
+				writeFile(t, dir, path, []byte(tt.file.current))
+			}
+
+			files := make(map[string]craft.File)
+			if tt.file.generated != "" {
+				files[path] = craft.File{Path: path, Content: []byte(tt.file.generated), Mode: craft.DefaultFileMode}
+			}
+
+			results, err := craft.Update(dir, lock, files)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+
+			if got := results[0]; got.Path != path || got.Action != tt.want || string(got.Content) != tt.content {
+				t.Errorf("got %s %s %q, want %s %s %q", got.Action, got.Path, got.Content, tt.want, path, tt.content)
+			}
+		})
+	}
+}
+
+func TestUpdateCreateOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", []byte("edited\n"))
+
+	files := map[string]craft.File{
+		"README.md": {Path: "README.md", Content: []byte("generated\n"), CreateOnly: true},
+	}
+
+	results, err := craft.Update(dir, craft.NewLock(craft.Data{}, nil), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Action != craft.UpdateUnchanged {
+		t.Errorf("got %v, want README.md unchanged", results)
+	}
+}
+
+func writeFile(t *testing.T, dir, path string, content []byte) {
+	t.Helper()
+
+	path = filepath.Join(dir, filepath.FromSlash(path))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
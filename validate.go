@@ -0,0 +1,232 @@
+package craft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// helmTemplatesDir holds the templates of the generated Helm chart.
+const helmTemplatesDir = "build/helm/templates"
+
+// validator checks the syntax of a generated file, returning a *FileError
+// when it is invalid.
+type validator func(path string, content []byte) error
+
+// Validate checks the syntax of every generated YAML, JSON, TOML, shell and
+// Terraform file and reports all the problems found at once.
+func Validate(files map[string][]byte) error {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var errs Errors
+
+	for _, p := range paths {
+		validate := validatorFor(p, files[p])
+		if validate == nil {
+			continue
+		}
+
+		errs = append(errs, flattenErrors(validate(p, files[p]))...)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// validatorFor picks the validator matching the type of a file, or nil when
+// the file is not checked.
+func validatorFor(name string, content []byte) validator {
+	switch path.Ext(name) {
+	case ".yml", ".yaml":
+		// The templates of the Helm chart only become YAML once rendered by
+		// Helm.
+		if strings.HasPrefix(name, helmTemplatesDir+"/") {
+			return nil
+		}
+
+		return validateYAML
+	case ".json":
+		return validateJSON
+	case ".toml":
+		return validateTOML
+	case ".tf":
+		return validateHCL
+	case ".sh", ".bash":
+		return validateShell
+	}
+
+	if path.Base(name) == "Taskfile" {
+		return validateYAML
+	}
+
+	if isShellScript(content) {
+		return validateShell
+	}
+
+	return nil
+}
+
+// isShellScript reports whether content starts with a sh or bash shebang.
+func isShellScript(content []byte) bool {
+	line, _, _ := bytes.Cut(content, []byte("\n"))
+	if !bytes.HasPrefix(line, []byte("#!")) {
+		return false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(string(line), "#!"))
+	if len(fields) == 0 {
+		return false
+	}
+
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+
+	return interpreter == "sh" || interpreter == "bash"
+}
+
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func validateYAML(name string, content []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		var node yaml.Node
+
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err == nil {
+			continue
+		}
+
+		fileErr := &FileError{Path: name, Err: err}
+		if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+			fileErr.Line, _ = strconv.Atoi(m[1])
+			fileErr.Err = errors.New(m[2])
+		}
+
+		return fileErr
+	}
+}
+
+func validateJSON(name string, content []byte) error {
+	var v interface{}
+
+	err := json.Unmarshal(content, &v)
+	if err == nil {
+		return nil
+	}
+
+	fileErr := &FileError{Path: name, Err: err}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset counts the byte the error was found at.
+		fileErr.Line, fileErr.Column = lineColumn(content, int(syntaxErr.Offset)-1)
+	}
+
+	return fileErr
+}
+
+func validateTOML(name string, content []byte) error {
+	var v map[string]interface{}
+
+	err := toml.Unmarshal(content, &v)
+	if err == nil {
+		return nil
+	}
+
+	fileErr := &FileError{Path: name, Err: err}
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		fileErr.Line, fileErr.Column = lineColumn(content, parseErr.Position.Start)
+		fileErr.Err = errors.New(parseErr.Message)
+	}
+
+	return fileErr
+}
+
+func validateShell(name string, content []byte) error {
+	parser := syntax.NewParser(syntax.Variant(syntax.LangBash))
+
+	_, err := parser.Parse(bytes.NewReader(content), name)
+	if err == nil {
+		return nil
+	}
+
+	fileErr := &FileError{Path: name, Err: err}
+
+	var parseErr syntax.ParseError
+	if errors.As(err, &parseErr) {
+		fileErr.Line, fileErr.Column = int(parseErr.Pos.Line()), int(parseErr.Pos.Col())
+		fileErr.Err = errors.New(parseErr.Text)
+	}
+
+	var langErr syntax.LangError
+	if errors.As(err, &langErr) {
+		fileErr.Line, fileErr.Column = int(langErr.Pos.Line()), int(langErr.Pos.Col())
+	}
+
+	return fileErr
+}
+
+func validateHCL(name string, content []byte) error {
+	_, diags := hclsyntax.ParseConfig(content, name, hcl.InitialPos)
+	if !diags.HasErrors() {
+		return nil
+	}
+
+	var errs Errors
+
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+
+		fileErr := &FileError{Path: name, Err: errors.New(diag.Summary)}
+		if diag.Detail != "" {
+			fileErr.Err = errors.New(diag.Summary + ": " + diag.Detail)
+		}
+		if diag.Subject != nil {
+			fileErr.Line, fileErr.Column = diag.Subject.Start.Line, diag.Subject.Start.Column
+		}
+
+		errs = append(errs, fileErr)
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errs
+}
+
+// lineColumn converts a byte offset of content into a 1-based line and
+// column.
+func lineColumn(content []byte, offset int) (int, int) {
+	offset = min(max(offset, 0), len(content))
+
+	line := bytes.Count(content[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(content[:offset], '\n')
+
+	return line, column
+}
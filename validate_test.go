@@ -0,0 +1,69 @@
+package craft_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edsonmichaque/craft"
+)
+
+func TestValidateFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{name: "yaml", path: "config/config.yml", content: "server:\n  port: 8080\n"},
+		{name: "yaml documents", path: "k8s.yaml", content: "a: 1\n---\nb: 2\n"},
+		{name: "invalid yaml", path: "config/config.yml", content: "server:\n  port: 8080\n port: 9090\n", want: "config/config.yml:2: did not find expected key"},
+		{name: "taskfile", path: "Taskfile", content: "version: '3'\n"},
+		{name: "invalid taskfile", path: "Taskfile", content: "tasks: [\n", want: "Taskfile:"},
+		{name: "helm template", path: "build/helm/templates/deployment.yml", content: "{{- if .Values.enabled }}\nkind: {{ .Values.kind }\n"},
+		{name: "json", path: "package.json", content: `{"name": "example"}`},
+		{name: "invalid json", path: "package.json", content: "{\n  \"name\": \"example\",\n}\n", want: "package.json:3:1: "},
+		{name: "toml", path: ".air.toml", content: "root = \".\"\n[build]\ncmd = \"go build\"\n"},
+		{name: "invalid toml", path: ".air.toml", content: "root = \".\"\n[build\n", want: ".air.toml:2:"},
+		{name: "hcl", path: "build/terraform/main.tf", content: "variable \"name\" {\n  default = \"example\"\n}\n"},
+		{name: "invalid hcl", path: "build/terraform/main.tf", content: "variable \"name\" {\n  default = \n}\n", want: "build/terraform/main.tf:2:13: Invalid expression"},
+		{name: "shell", path: "scripts/lib/common.sh", content: "log() {\n  echo \"$@\"\n}\n"},
+		{name: "invalid shell", path: "scripts/lib/common.sh", content: "if true; then\n  echo ok\n", want: "scripts/lib/common.sh:1:1: "},
+		{name: "shebang", path: "scripts/build", content: "#!/usr/bin/env bash\necho ok\n"},
+		{name: "invalid shebang", path: "scripts/build", content: "#!/bin/sh\necho $(\n", want: "scripts/build:2:"},
+		{name: "other interpreter", path: "scripts/tool", content: "#!/usr/bin/env python3\nif x:\n"},
+		{name: "unchecked", path: "README.md", content: "{ [ unbalanced\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := craft.Validate(map[string][]byte{tt.path: []byte(tt.content)})
+
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+
+				return
+			}
+
+			assertErrors(t, err, []string{tt.want})
+
+			var fileErr *craft.FileError
+			if !errors.As(err, &fileErr) || fileErr.Path != tt.path {
+				t.Errorf("got error %v, want a FileError for %s", err, tt.path)
+			}
+		})
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	err := craft.Validate(map[string][]byte{
+		"b.json":  []byte("{"),
+		"a.yml":   []byte("a: [\n"),
+		"ok.toml": []byte("a = 1\n"),
+		"c.tf":    []byte("resource {\n"),
+	})
+
+	// Every invalid file is reported, sorted by path.
+	assertErrors(t, err, []string{"a.yml:", "b.json:", "c.tf:"})
+}